@@ -0,0 +1,183 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Breathing phase kinds
+const (
+	phaseInhale = "inhale"
+	phaseSip    = "sip" // short top-up inhale, as in the physiological sigh
+	phaseHold   = "hold"
+	phaseExhale = "exhale"
+	phasePause  = "pause" // hold with empty lungs
+)
+
+const defaultPatternID = "478"
+
+// minPhaseSeconds is the shortest phase a custom pattern may have
+const minPhaseSeconds = 0.1
+
+// BreathPhase is one step of a breathing pattern
+type BreathPhase struct {
+	Kind    string
+	Seconds float64
+}
+
+// duration returns the phase length as a time.Duration
+func (p BreathPhase) duration() time.Duration {
+	return time.Duration(p.Seconds * float64(time.Second))
+}
+
+// BreathingPattern is a repeating sequence of breathing phases
+type BreathingPattern struct {
+	ID      string
+	Name    string // locale key, or a literal label for custom patterns
+	Aliases []string
+	Phases  []BreathPhase
+}
+
+// breathingPatterns is the registry of named patterns
+var breathingPatterns = []*BreathingPattern{
+	{
+		ID:      "478",
+		Name:    "pattern_478",
+		Aliases: []string{"4-7-8", "relax"},
+		Phases: []BreathPhase{
+			{phaseInhale, 4}, {phaseHold, 7}, {phaseExhale, 8}, {phasePause, 2},
+		},
+	},
+	{
+		ID:      "box",
+		Name:    "pattern_box",
+		Aliases: []string{"square", "4-4-4-4"},
+		Phases: []BreathPhase{
+			{phaseInhale, 4}, {phaseHold, 4}, {phaseExhale, 4}, {phasePause, 4},
+		},
+	},
+	{
+		ID:      "coherent",
+		Name:    "pattern_coherent",
+		Aliases: []string{"resonance", "5.5-5.5"},
+		Phases: []BreathPhase{
+			{phaseInhale, 5.5}, {phaseExhale, 5.5},
+		},
+	},
+	{
+		ID:      "sigh",
+		Name:    "pattern_sigh",
+		Aliases: []string{"physiological-sigh"},
+		Phases: []BreathPhase{
+			{phaseInhale, 2}, {phaseSip, 1}, {phaseExhale, 6},
+		},
+	},
+}
+
+// customPhaseKinds maps the positions of a custom pattern to phase kinds,
+// keyed by how many numbers were given
+var customPhaseKinds = map[int][]string{
+	2: {phaseInhale, phaseExhale},
+	3: {phaseInhale, phaseHold, phaseExhale},
+	4: {phaseInhale, phaseHold, phaseExhale, phasePause},
+}
+
+// FindBreathingPattern looks up a pattern by ID or alias, or parses a
+// custom pattern such as "4,7,8,2"
+func FindBreathingPattern(localizer *Localizer, name string) (*BreathingPattern, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = defaultPatternID
+	}
+
+	for _, p := range breathingPatterns {
+		if p.ID == name {
+			return p, nil
+		}
+		for _, alias := range p.Aliases {
+			if alias == name {
+				return p, nil
+			}
+		}
+	}
+
+	if strings.Contains(name, ",") {
+		return ParseBreathingPattern(localizer, name)
+	}
+	return nil, errors.New(localizer.Tf("pattern_unknown", name))
+}
+
+// ParseBreathingPattern builds a custom pattern from comma-separated
+// seconds for inhale, hold, exhale and pause. Fewer numbers drop the
+// holds (e.g. "5,5" is inhale/exhale) and zero skips a phase. Other
+// phases must last at least minPhaseSeconds.
+func ParseBreathingPattern(localizer *Localizer, spec string) (*BreathingPattern, error) {
+	fields := strings.Split(spec, ",")
+	kinds, ok := customPhaseKinds[len(fields)]
+	if !ok {
+		return nil, errors.New(localizer.Tf("pattern_count", spec))
+	}
+
+	pattern := &BreathingPattern{ID: "custom"}
+	var labels []string
+	hasExhale := false
+	for i, field := range fields {
+		seconds, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || math.IsNaN(seconds) || math.IsInf(seconds, 0) || seconds < 0 {
+			return nil, errors.New(localizer.Tf("pattern_invalid_seconds", spec, field))
+		}
+		if seconds > 0 && seconds < minPhaseSeconds {
+			return nil, errors.New(localizer.Tf("pattern_too_short", spec, field, minPhaseSeconds))
+		}
+		labels = append(labels, formatSeconds(seconds))
+		if seconds == 0 {
+			continue
+		}
+		// Huge numbers overflow to a non-positive length once converted
+		phase := BreathPhase{kinds[i], seconds}
+		if phase.duration() <= 0 {
+			return nil, errors.New(localizer.Tf("pattern_invalid_seconds", spec, field))
+		}
+		if kinds[i] == phaseExhale {
+			hasExhale = true
+		}
+		pattern.Phases = append(pattern.Phases, phase)
+	}
+	if len(pattern.Phases) == 0 || pattern.Phases[0].Kind != phaseInhale {
+		return nil, errors.New(localizer.Tf("pattern_no_inhale", spec))
+	}
+	if !hasExhale {
+		return nil, errors.New(localizer.Tf("pattern_no_exhale", spec))
+	}
+	pattern.Name = strings.Join(labels, "-")
+
+	return pattern, nil
+}
+
+// cycleEnd returns the index of the phase whose completion counts one
+// breath cycle: the last exhale, or the last phase if there is none
+func (p *BreathingPattern) cycleEnd() int {
+	for i := len(p.Phases) - 1; i >= 0; i-- {
+		if p.Phases[i].Kind == phaseExhale {
+			return i
+		}
+	}
+	return len(p.Phases) - 1
+}
+
+// Tips returns the localized tips describing the pattern
+func (p *BreathingPattern) Tips(localizer *Localizer) []string {
+	tips := []string{localizer.Tf("tip_breathe_pattern", localizer.T(p.Name))}
+	for _, phase := range p.Phases {
+		tips = append(tips, localizer.Tf("tip_"+phase.Kind, formatSeconds(phase.Seconds)))
+	}
+	return tips
+}
+
+// formatSeconds prints seconds without trailing zeros, e.g. 4 or 5.5
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', -1, 64)
+}
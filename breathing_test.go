@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestParseBreathingPattern(t *testing.T) {
+	localizer := newTestLocalizer(t)
+	tests := []struct {
+		spec   string
+		name   string
+		phases int
+		ok     bool
+	}{
+		{"4,7,8", "4-7-8", 3, true},
+		{"5,5", "5-5", 2, true},
+		{"4,4,4,4", "4-4-4-4", 4, true},
+		{"4,0,6", "4-0-6", 2, true},
+		{"0.1,0.1", "0.1-0.1", 2, true},
+		{"4", "", 0, false},
+		{"4,4,4,4,4", "", 0, false},
+		{"0,5", "", 0, false},
+		{"5,0", "", 0, false},
+		{"5,-1", "", 0, false},
+		{"5,x", "", 0, false},
+		{"NaN,5", "", 0, false},
+		{"5,Inf", "", 0, false},
+		{"-Inf,5", "", 0, false},
+		{"1e-10,1e-10", "", 0, false},
+		{"5,0.05", "", 0, false},
+		{"1e30,5", "", 0, false},
+	}
+	for _, tt := range tests {
+		pattern, err := ParseBreathingPattern(localizer, tt.spec)
+		if !tt.ok {
+			if err == nil {
+				t.Errorf("ParseBreathingPattern(%q) succeeded, want an error", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseBreathingPattern(%q): %v", tt.spec, err)
+			continue
+		}
+		if pattern.Name != tt.name || len(pattern.Phases) != tt.phases {
+			t.Errorf("ParseBreathingPattern(%q) = %s with %d phases, want %s with %d", tt.spec, pattern.Name, len(pattern.Phases), tt.name, tt.phases)
+		}
+	}
+}
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+	"time"
+)
+
+// version is set at build time with -ldflags "-X main.version=v1.2.3"
+var version = "dev"
+
+// command is one subcommand of the binary. Its help text comes from the
+// cmd_<name>_summary and cmd_<name>_usage locale keys.
+type command struct {
+	name string
+	run  func(localizer *Localizer, args []string) error
+}
+
+// commands lists the subcommands in the order help shows them
+var commands []command
+
+func init() {
+	commands = []command{
+		{"run", runCommand},
+		{"list", listCommand},
+		{"stats", statsCommand},
+		{"history", historyCommand},
+		{"export", exportCommand},
+		{"config", configCommand},
+		{"validate", validateCommand},
+		{"validate-locales", validateLocalesCommand},
+		{"version", versionCommand},
+		{"help", helpCommand},
+	}
+}
+
+// runCLI dispatches to the subcommand named by the first argument. Without
+// one, the arguments are flags for run, so "terminal-gym --reps=20" keeps
+// working.
+func runCLI(localizer *Localizer, args []string) error {
+	// --lang has already been applied and may come before the command
+	args = withoutLang(args)
+
+	name := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	} else if len(args) > 0 && isHelpFlag(args[0]) {
+		name, args = "help", nil
+	}
+
+	cmd, ok := findCommand(name)
+	if !ok {
+		return errors.New(localizer.Tf("unknown_command", name))
+	}
+	return cmd.run(localizer, args)
+}
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func isHelpFlag(arg string) bool {
+	switch arg {
+	case "-h", "-help", "--help", "--h":
+		return true
+	}
+	return false
+}
+
+// langFromArgs finds the --lang flag before the flags are parsed, so the
+// help text of the flags themselves can be localized. Without the flag it
+// returns the configured language.
+func langFromArgs(args []string) string {
+	for i, arg := range args {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "lang" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return config.Lang
+}
+
+// withoutLang removes the --lang flag and its value from args
+func withoutLang(args []string) []string {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		name, _, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !strings.HasPrefix(args[i], "-") || name != "lang" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue {
+			i++
+		}
+	}
+	return rest
+}
+
+// newFlagSet creates the flags of a subcommand. Every subcommand accepts
+// --lang, and --help prints its localized usage.
+func newFlagSet(name string, localizer *Localizer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.String("lang", config.Lang, localizer.T("flag_lang"))
+	fs.Usage = func() {
+		out := fs.Output()
+		fmt.Fprintln(out, localizer.T("cmd_"+name+"_usage"))
+		fmt.Fprintln(out, "\n"+localizer.T("flags_header"))
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// warn reports a problem that doesn't stop the command on standard
+// error, so it never mixes with the output of commands such as export
+func warn(title string, err error) {
+	fmt.Fprintln(os.Stderr, title)
+	fmt.Fprintf(os.Stderr, "%v\n\n", err)
+}
+
+// loadDefinitions registers the exercise definition files. Broken files
+// are reported but don't stop the other exercises from working.
+func loadDefinitions(localizer *Localizer) error {
+	definitions, loadErr := LoadExerciseDefinitions(exerciseDirs()...)
+	return errors.Join(loadErr, RegisterDefinitions(definitions))
+}
+
+// runCommand plays an exercise or a program
+func runCommand(localizer *Localizer, args []string) error {
+	fs := newFlagSet("run", localizer)
+	reps := fs.Int("reps", 0, localizer.T("flag_reps"))
+	sets := fs.Int("sets", defaultSets, localizer.T("flag_sets"))
+	rest := fs.Duration("rest", defaultRest, localizer.T("flag_rest"))
+	pattern := fs.String("pattern", "", localizer.T("flag_pattern"))
+	programName := fs.String("program", "", localizer.T("flag_program"))
+	exerciseID := fs.String("exercise", config.Exercise, localizer.T("flag_exercise"))
+	duration := fs.Duration("duration", 0, localizer.T("flag_duration"))
+	noCountdown := fs.Bool("no-countdown", false, localizer.T("flag_no_countdown"))
+	countdown := fs.Int("countdown", config.Countdown, localizer.T("flag_countdown"))
+	tempo := fs.Float64("tempo", config.Tempo, localizer.T("flag_tempo"))
+	color := fs.String("color", config.Color, localizer.T("flag_color"))
+	fs.Parse(args)
+
+	if *duration < 0 {
+		return fmt.Errorf("%s %s", localizer.T("exercise_error"), localizer.Tf("flag_negative", "duration"))
+	}
+	if *countdown < 0 {
+		return fmt.Errorf("%s %s", localizer.T("exercise_error"), localizer.Tf("flag_negative", "countdown"))
+	}
+	// Negated so that NaN, which fails every comparison, is rejected too
+	if !(*tempo >= minTempo && *tempo <= maxTempo) {
+		return fmt.Errorf("%s %s", localizer.T("exercise_error"), localizer.Tf("flag_range", "tempo", minTempo, maxTempo))
+	}
+	colorMode, err := parseColorMode(*color)
+	if err != nil {
+		return fmt.Errorf("%s %s", localizer.T("exercise_error"), localizer.Tf("flag_choice", "color", strings.Join(colorModes, ", ")))
+	}
+
+	// Validate the breathing pattern before showing any menu
+	var breathPattern *BreathingPattern
+	if *pattern != "" {
+		var err error
+		breathPattern, err = FindBreathingPattern(localizer, *pattern)
+		if err != nil {
+			return fmt.Errorf("%s %w", localizer.T("pattern_error"), err)
+		}
+	}
+
+	if err := loadDefinitions(localizer); err != nil {
+		warn(localizer.T("exercise_load_warning"), err)
+		time.Sleep(2 * time.Second)
+	}
+
+	gym := NewTerminalGym(localizer)
+	if path, err := historyPath(); err == nil {
+		gym.historyPath = path
+	}
+	gym.tempo = *tempo
+	gym.color = colorEnabled(colorMode, os.Stdout)
+
+	if *programName != "" {
+		// A program replaces the exercise menu
+		programs, err := LoadPrograms(programDirs()...)
+		if err != nil {
+			warn(localizer.T("program_load_warning"), err)
+		}
+		program, err := FindProgram(localizer, *programName, programs)
+		if err == nil {
+			err = gym.useProgram(program)
+		}
+		if err != nil {
+			return fmt.Errorf("%s %w", localizer.T("program_error"), err)
+		}
+	} else {
+		// Exercise selection, unless one was given on the command line
+		if *exerciseID != "" {
+			if err := gym.chooseExercise(*exerciseID); err != nil {
+				return fmt.Errorf("%s %w", localizer.T("exercise_error"), err)
+			}
+		} else if !gym.selectExercise() {
+			return nil
+		}
+		// A duration on its own replaces the default rep target
+		if *reps != 0 {
+			gym.currentExercise.SetTarget(max(*reps, 0))
+		} else if *duration > 0 {
+			gym.currentExercise.SetTarget(0)
+		}
+		if meditation, ok := gym.currentExercise.(*MeditationExercise); ok && breathPattern != nil {
+			meditation.SetPattern(breathPattern)
+		} else if breathPattern != nil && *exerciseID != "" {
+			return fmt.Errorf("%s %s", localizer.T("pattern_error"), localizer.Tf("pattern_not_used", gym.currentExercise.GetName()))
+		}
+		gym.useExercise(*sets, *rest)
+		gym.steps[0].TimeLimit = *duration
+	}
+
+	// Preparation phase
+	if !*noCountdown && *countdown > 0 {
+		gym.countdown(*countdown)
+	}
+
+	gym.run()
+	return nil
+}
+
+// listCommand prints every registered exercise
+func listCommand(localizer *Localizer, args []string) error {
+	fs := newFlagSet("list", localizer)
+	markdown := fs.Bool("markdown", false, localizer.T("flag_markdown"))
+	fs.Parse(args)
+
+	if err := loadDefinitions(localizer); err != nil {
+		warn(localizer.T("exercise_load_warning"), err)
+	}
+	listExercises(os.Stdout, localizer, *markdown)
+	return nil
+}
+
+// versionCommand prints the version of the binary
+func versionCommand(localizer *Localizer, args []string) error {
+	fs := newFlagSet("version", localizer)
+	fs.Parse(args)
+
+	v := version
+	if info, ok := debug.ReadBuildInfo(); ok && v == "dev" && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		v = info.Main.Version
+	}
+	fmt.Printf("terminal-gym %s\n", v)
+	return nil
+}
+
+// helpCommand lists the subcommands, or shows the usage of one of them
+func helpCommand(localizer *Localizer, args []string) error {
+	fs := newFlagSet("help", localizer)
+	fs.Parse(args)
+	args = fs.Args()
+
+	if len(args) > 0 {
+		cmd, ok := findCommand(args[0])
+		if !ok {
+			return errors.New(localizer.Tf("unknown_command", args[0]))
+		}
+		// Every command prints its usage and exits on --help
+		return cmd.run(localizer, []string{"--help"})
+	}
+
+	fmt.Println(localizer.T("help_intro"))
+	fmt.Println("\n" + localizer.T("commands_header"))
+	width := 0
+	for _, cmd := range commands {
+		width = max(width, len(cmd.name))
+	}
+	for _, cmd := range commands {
+		fmt.Printf("  %-*s  %s\n", width, cmd.name, localizer.T("cmd_"+cmd.name+"_summary"))
+	}
+	fmt.Println("\n" + localizer.T("help_footer"))
+	fmt.Println(localizer.T("language_help"))
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import "time"
+
+const (
+	// physicsStep is the fixed interval the harmonica springs are tuned for
+	physicsStep = time.Second / fps
+
+	// maxPhysicsLag caps how much simulation is caught up after a stall so
+	// a frozen terminal doesn't trigger a burst of thousands of steps
+	maxPhysicsLag = 250 * time.Millisecond
+)
+
+// Clock is the source of wall-clock time for the animation loop.
+// It is injectable so timing can be driven deterministically.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock reads the system time
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// fixedStep converts variable frame deltas into a whole number of fixed
+// physics steps, carrying the remainder over to the next frame. Phase
+// timing uses the real delta; only the springs are stepped at a fixed rate.
+type fixedStep struct {
+	accumulated time.Duration
+}
+
+// Steps adds dt and returns how many physics steps are now due
+func (fs *fixedStep) Steps(dt time.Duration) int {
+	fs.accumulated += dt
+	if fs.accumulated > maxPhysicsLag {
+		fs.accumulated = maxPhysicsLag
+	}
+
+	steps := int(fs.accumulated / physicsStep)
+	fs.accumulated -= time.Duration(steps) * physicsStep
+	return steps
+}
+
+// Reset drops any accumulated time
+func (fs *fixedStep) Reset() {
+	fs.accumulated = 0
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFixedStep(t *testing.T) {
+	var fs fixedStep
+	if got := fs.Steps(physicsStep / 2); got != 0 {
+		t.Errorf("half a step = %d steps, want 0", got)
+	}
+	if got := fs.Steps(physicsStep - physicsStep/2); got != 1 {
+		t.Errorf("the carried half and another half = %d steps, want 1", got)
+	}
+	if got := fs.Steps(3 * physicsStep); got != 3 {
+		t.Errorf("three steps at once = %d steps, want 3", got)
+	}
+
+	// A stall is only caught up to maxPhysicsLag
+	want := int(maxPhysicsLag / physicsStep)
+	if got := fs.Steps(10 * time.Second); got != want {
+		t.Errorf("a 10s stall = %d steps, want %d", got, want)
+	}
+	if got := fs.Steps(0); got != 0 {
+		t.Errorf("after a stall %d steps are still due, want 0", got)
+	}
+
+	fs.Steps(physicsStep / 2)
+	fs.Reset()
+	if got := fs.Steps(physicsStep / 2); got != 0 {
+		t.Errorf("after Reset = %d steps, want 0", got)
+	}
+}
+
+func TestMeditationLateFrame(t *testing.T) {
+	me := NewMeditationExercise(newTestLocalizer(t))
+
+	// One frame 5s late on 4-7-8: the inhale is over and 1s of the hold
+	// has passed
+	me.Update(5 * time.Second)
+	if me.phase != phaseHold || me.phaseElapsed != time.Second {
+		t.Errorf("after 5s: %s with %v elapsed, want hold with 1s", me.phase, me.phaseElapsed)
+	}
+	if me.breathCycles != 0 {
+		t.Errorf("after 5s: %d cycles, want 0", me.breathCycles)
+	}
+}
+
+func TestMeditationCatchUp(t *testing.T) {
+	me := NewMeditationExercise(newTestLocalizer(t))
+
+	// A 4-7-8 cycle with its pause is 21s, counted when the exhale ends
+	tests := []struct {
+		dt      time.Duration
+		phase   string
+		elapsed time.Duration
+		cycles  int
+	}{
+		{18 * time.Second, phaseExhale, 7 * time.Second, 0},
+		{time.Second, phasePause, 0, 1},
+		{44 * time.Second, phaseInhale, 0, 3},
+		{20*time.Second + 500*time.Millisecond, phasePause, 1500 * time.Millisecond, 4},
+	}
+	for i, tt := range tests {
+		me.Update(tt.dt)
+		if me.phase != tt.phase || me.phaseElapsed != tt.elapsed || me.breathCycles != tt.cycles {
+			t.Errorf("update %d (%v): %s with %v elapsed and %d cycles, want %s with %v and %d",
+				i+1, tt.dt, me.phase, me.phaseElapsed, me.breathCycles, tt.phase, tt.elapsed, tt.cycles)
+		}
+	}
+}
+
+func TestDefinedExerciseCatchUp(t *testing.T) {
+	calf, err := LoadExerciseDefinition(filepath.Join("exercises", "calf_raises.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	de := NewDefinedExercise(calf, newTestLocalizer(t))
+	de.SetTarget(0)
+
+	// rise 1.5s, hold 1s, lower 2s, rest 0.5s: 12s is two reps and half a
+	// second into the hold
+	de.Update(12 * time.Second)
+	if de.Count != 2 {
+		t.Errorf("reps = %d, want 2", de.Count)
+	}
+	if name := calf.Phases[de.phase].Name; name != "hold" || de.phaseElapsed != 500*time.Millisecond {
+		t.Errorf("phase = %s with %v elapsed, want hold with 500ms", name, de.phaseElapsed)
+	}
+}
+
+func TestSessionLateFrames(t *testing.T) {
+	localizer := newTestLocalizer(t)
+	clock := &fixedClock{now: time.Date(2026, 3, 4, 8, 0, 0, 0, time.Local)}
+
+	gym := NewTerminalGym(localizer)
+	gym.setOutput(&bytes.Buffer{})
+	gym.layout = Layout{Width: 80, Height: 50}
+	gym.clock = clock
+	if err := gym.chooseExercise("meditation"); err != nil {
+		t.Fatal(err)
+	}
+	gym.currentExercise.SetTarget(2)
+	gym.useExercise(1, 0)
+	gym.startStep(0)
+	me := gym.currentExercise.(*MeditationExercise)
+
+	// advance moves the clock by dt and delivers it as one frame
+	advance := func(dt time.Duration) bool {
+		clock.now = clock.now.Add(dt)
+		return gym.tick(clock.now, dt)
+	}
+
+	if advance(5 * time.Second) {
+		t.Fatal("session ended after 5s")
+	}
+	if me.phase != phaseHold || me.phaseElapsed != time.Second {
+		t.Errorf("after 5s: %s with %v elapsed, want hold with 1s", me.phase, me.phaseElapsed)
+	}
+
+	// Paused time doesn't move the exercise on
+	gym.paused = true
+	advance(time.Minute)
+	gym.paused = false
+	if me.phase != phaseHold || me.phaseElapsed != time.Second {
+		t.Errorf("after a pause: %s with %v elapsed, want hold with 1s", me.phase, me.phaseElapsed)
+	}
+
+	// Two cycles end 40s in; one late frame gets there
+	if !advance(35 * time.Second) {
+		t.Fatal("session still running after two cycles")
+	}
+	if gym.activeFor != 40*time.Second || gym.pausedFor != time.Minute {
+		t.Errorf("active %v and paused %v, want 40s and 1m", gym.activeFor, gym.pausedFor)
+	}
+	if got := gym.workout.TotalProgress(); got != 2 {
+		t.Errorf("cycles = %d, want 2", got)
+	}
+}
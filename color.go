@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Color modes of the color setting
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// colorModes lists the values of the color setting
+var colorModes = []string{colorAuto, colorAlways, colorNever}
+
+// Text styles, as SGR escape sequences
+const (
+	styleReset    = "\033[0m"
+	styleTitle    = "\033[1;36m"
+	styleSelected = "\033[1;32m"
+	stylePaused   = "\033[1;33m"
+)
+
+// parseColorMode checks a value of the color setting
+func parseColorMode(mode string) (string, error) {
+	switch mode {
+	case colorAuto, colorAlways, colorNever:
+		return mode, nil
+	}
+	return "", fmt.Errorf("must be %s, %s or %s", colorAuto, colorAlways, colorNever)
+}
+
+// colorEnabled decides whether to color the output to out. In auto mode
+// colors are used on terminals unless $NO_COLOR is set.
+func colorEnabled(mode string, out io.Writer) bool {
+	switch mode {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	f, ok := out.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// paint wraps s in style when colors are on
+func paint(on bool, style, s string) string {
+	if !on || s == "" {
+		return s
+	}
+	return style + s + styleReset
+}
+
+// cutStyle splits a leading SGR escape sequence such as "\033[1;36m" off
+// s. It returns ok false if s doesn't start with one.
+func cutStyle(s string) (style, rest string, ok bool) {
+	if !strings.HasPrefix(s, "\033[") {
+		return "", s, false
+	}
+	end := strings.IndexFunc(s[2:], func(r rune) bool {
+		return (r < '0' || r > '9') && r != ';'
+	})
+	if end < 0 || s[2+end] != 'm' {
+		return "", s, false
+	}
+	return s[:3+end], s[3+end:], true
+}
+
+// stripStyles removes the SGR escape sequences from s
+func stripStyles(s string) string {
+	if !strings.Contains(s, "\033[") {
+		return s
+	}
+	var b strings.Builder
+	for s != "" {
+		if _, rest, ok := cutStyle(s); ok {
+			s = rest
+			continue
+		}
+		i := strings.Index(s[1:], "\033[")
+		if i < 0 {
+			b.WriteString(s)
+			break
+		}
+		b.WriteString(s[:i+1])
+		s = s[i+1:]
+	}
+	return b.String()
+}
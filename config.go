@@ -0,0 +1,414 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Config file names, looked up in this order
+const (
+	configTOML = "config.toml"
+	configJSON = "config.json"
+)
+
+// Config holds the settings that can come from the config file and the
+// environment. Flags are applied on top by using these as their defaults.
+type Config struct {
+	Lang      string
+	Exercise  string
+	Tempo     float64
+	Color     string
+	Countdown int
+}
+
+// defaultConfig returns the settings used when nothing else is
+// configured. The language comes from the locale environment variables.
+func defaultConfig() Config {
+	lang := detectLanguage()
+	if lang == "" {
+		lang = referenceLocale
+	}
+	return Config{
+		Lang:      lang,
+		Tempo:     1,
+		Color:     colorAuto,
+		Countdown: 3,
+	}
+}
+
+// config is the configuration in effect, loaded in main before the
+// commands run
+var config = defaultConfig()
+
+// setting is one configurable value: its key in the config file, the
+// environment variable that overrides it, and how to read and write it
+type setting struct {
+	key     string
+	env     string
+	numeric bool
+	get     func(c *Config) string
+	set     func(c *Config, value string) error
+}
+
+// settings lists the configurable values in the order config get shows them
+var settings = []setting{
+	{
+		key: "lang",
+		env: "TERMINAL_GYM_LANG",
+		get: func(c *Config) string { return c.Lang },
+		set: func(c *Config, value string) error {
+			if value == "" {
+				return fmt.Errorf("must not be empty")
+			}
+			c.Lang = value
+			return nil
+		},
+	},
+	{
+		key: "exercise",
+		env: "TERMINAL_GYM_EXERCISE",
+		get: func(c *Config) string { return c.Exercise },
+		set: func(c *Config, value string) error {
+			c.Exercise = value
+			return nil
+		},
+	},
+	{
+		key:     "tempo",
+		env:     "TERMINAL_GYM_TEMPO",
+		numeric: true,
+		get:     func(c *Config) string { return strconv.FormatFloat(c.Tempo, 'g', -1, 64) },
+		set: func(c *Config, value string) error {
+			tempo, err := strconv.ParseFloat(value, 64)
+			if err != nil || !(tempo >= minTempo && tempo <= maxTempo) {
+				return fmt.Errorf("must be a number from %g to %g", minTempo, maxTempo)
+			}
+			c.Tempo = tempo
+			return nil
+		},
+	},
+	{
+		key: "color",
+		env: "TERMINAL_GYM_COLOR",
+		get: func(c *Config) string { return c.Color },
+		set: func(c *Config, value string) error {
+			if _, err := parseColorMode(value); err != nil {
+				return err
+			}
+			c.Color = value
+			return nil
+		},
+	},
+	{
+		key:     "countdown",
+		env:     "TERMINAL_GYM_COUNTDOWN",
+		numeric: true,
+		get:     func(c *Config) string { return strconv.Itoa(c.Countdown) },
+		set: func(c *Config, value string) error {
+			seconds, err := strconv.Atoi(value)
+			if err != nil || seconds < 0 {
+				return fmt.Errorf("must be a whole number of seconds, 0 or more")
+			}
+			c.Countdown = seconds
+			return nil
+		},
+	},
+}
+
+func findSetting(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// configPath returns the config file in use: config.toml or config.json
+// in $XDG_CONFIG_HOME/terminal-gym, whichever exists, preferring TOML.
+// Without either it returns where config set creates one.
+func configPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("finding the config directory: %w", err)
+	}
+	dir := filepath.Join(configDir, "terminal-gym")
+	for _, name := range []string{configTOML, configJSON} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return filepath.Join(dir, name), nil
+		}
+	}
+	return filepath.Join(dir, configTOML), nil
+}
+
+// LoadConfig resolves the settings: the defaults, overridden by the
+// config file, overridden by the environment. Invalid values are
+// reported together and leave the previous value in place.
+func LoadConfig() (Config, error) {
+	c := defaultConfig()
+	var errs []error
+
+	path, err := configPath()
+	if err != nil {
+		return c, err
+	}
+	values, err := readConfigFile(path)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, key := range sortedKeys(values) {
+		s, ok := findSetting(key)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %q", path, key))
+			continue
+		}
+		if err := s.set(&c, values[key]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %s %w", path, key, err))
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok {
+			if err := s.set(&c, value); err != nil {
+				errs = append(errs, fmt.Errorf("$%s %w", s.env, err))
+			}
+		}
+	}
+	return c, errors.Join(errs...)
+}
+
+// readConfigFile reads the raw values of a config file. A missing file
+// has no values.
+func readConfigFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	if filepath.Ext(path) == ".json" {
+		return parseConfigJSON(path, data)
+	}
+	return parseConfigTOML(path, data)
+}
+
+// parseConfigJSON reads a flat JSON object of settings
+func parseConfigJSON(path string, data []byte) (map[string]string, error) {
+	var raw map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	values := map[string]string{}
+	for key, value := range raw {
+		switch v := value.(type) {
+		case string:
+			values[key] = v
+		case json.Number:
+			values[key] = v.String()
+		default:
+			return nil, fmt.Errorf("%s: %s must be a string or a number", path, key)
+		}
+	}
+	return values, nil
+}
+
+// parseConfigTOML reads the subset of TOML the settings need: one
+// key = value per line, with quoted strings, numbers and # comments
+func parseConfigTOML(path string, data []byte) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(text, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected key = value", path, line)
+		}
+		key = strings.TrimSpace(key)
+		value, err := parseTOMLValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s: %w", path, line, key, err)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
+
+// parseTOMLValue unquotes a string value, or returns a bare value such as
+// a number as it is. Trailing comments are dropped.
+func parseTOMLValue(value string) (string, error) {
+	if strings.HasPrefix(value, `"`) {
+		end := 1
+		for end < len(value) && (value[end] != '"' || value[end-1] == '\\') {
+			end++
+		}
+		if end == len(value) {
+			return "", fmt.Errorf("unterminated string")
+		}
+		rest := strings.TrimSpace(value[end+1:])
+		if rest != "" && !strings.HasPrefix(rest, "#") {
+			return "", fmt.Errorf("unexpected %q after the value", rest)
+		}
+		return strconv.Unquote(value[:end+1])
+	}
+	value, _, _ = strings.Cut(value, "#")
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("missing value")
+	}
+	return value, nil
+}
+
+// SetConfigValue validates a setting and writes it to the config file,
+// creating the file if needed. In a TOML file the other lines, comments
+// included, are kept as they are.
+func SetConfigValue(path, key, value string) error {
+	s, ok := findSetting(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	c := defaultConfig()
+	if err := s.set(&c, value); err != nil {
+		return fmt.Errorf("%s %w", key, err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+	if filepath.Ext(path) == ".json" {
+		if data, err = setConfigJSON(path, data, s, value); err != nil {
+			return err
+		}
+	} else {
+		data = setConfigTOML(data, s, value)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write config %s: %w", path, err)
+	}
+	return nil
+}
+
+func setConfigJSON(path string, data []byte, s setting, value string) ([]byte, error) {
+	raw := map[string]any{}
+	if len(data) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	}
+	if s.numeric {
+		raw[s.key] = json.Number(value)
+	} else {
+		raw[s.key] = value
+	}
+	data, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return append(data, '\n'), nil
+}
+
+func setConfigTOML(data []byte, s setting, value string) []byte {
+	line := s.key + " = " + strconv.Quote(value)
+	if s.numeric {
+		line = s.key + " = " + value
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(data) == 0 {
+		lines = nil
+	}
+	for i, l := range lines {
+		if key, _, ok := strings.Cut(l, "="); ok && strings.TrimSpace(key) == s.key {
+			lines[i] = line
+			return []byte(strings.Join(lines, "\n") + "\n")
+		}
+	}
+	return []byte(strings.Join(append(lines, line), "\n") + "\n")
+}
+
+// configCommand shows and changes the settings in the config file
+func configCommand(localizer *Localizer, args []string) error {
+	fs := newFlagSet("config", localizer)
+	fs.Parse(args)
+	args = fs.Args()
+
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	action := ""
+	if len(args) > 0 {
+		action, args = args[0], args[1:]
+	}
+
+	switch {
+	case action == "path" && len(args) == 0:
+		fmt.Println(path)
+	case action == "get" && len(args) == 0:
+		for _, s := range settings {
+			fmt.Println(strings.TrimSpace(s.key + " = " + s.get(&config)))
+		}
+	case action == "get" && len(args) == 1:
+		s, ok := findSetting(args[0])
+		if !ok {
+			return fmt.Errorf("%s %s", localizer.T("config_error"), localizer.Tf("config_unknown_setting", args[0], strings.Join(settingKeys(), ", ")))
+		}
+		fmt.Println(s.get(&config))
+	case action == "set" && len(args) == 2:
+		s, ok := findSetting(args[0])
+		if !ok {
+			return fmt.Errorf("%s %s", localizer.T("config_error"), localizer.Tf("config_unknown_setting", args[0], strings.Join(settingKeys(), ", ")))
+		}
+		if err := s.set(&Config{}, args[1]); err != nil {
+			return fmt.Errorf("%s %s", localizer.T("config_error"), localizer.Tf("config_invalid_value", args[1], args[0]))
+		}
+		// An unknown exercise would make every run fail, so it is checked
+		// against the registry, definition files included
+		if s.key == "exercise" && args[1] != "" {
+			if err := loadDefinitions(localizer); err != nil {
+				warn(localizer.T("exercise_load_warning"), err)
+			}
+			if _, ok := FindExercise(args[1]); !ok {
+				return fmt.Errorf("%s %s", localizer.T("config_error"), localizer.Tf("exercise_unknown", args[1], strings.Join(exerciseIDs(), ", ")))
+			}
+		}
+		if err := SetConfigValue(path, args[0], args[1]); err != nil {
+			return fmt.Errorf("%s %w", localizer.T("config_error"), err)
+		}
+		fmt.Println(localizer.Tf("config_saved", args[0], args[1], path))
+	default:
+		fs.Usage()
+		return errors.New(localizer.T("config_usage_error"))
+	}
+	return nil
+}
+
+// settingKeys lists the keys of every setting, sorted
+func settingKeys() []string {
+	keys := make([]string, len(settings))
+	for i, s := range settings {
+		keys[i] = s.key
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTOMLValue(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		ok    bool
+	}{
+		{`"zh"`, "zh", true},
+		{`1.5`, "1.5", true},
+		{`3 # seconds`, "3", true},
+		{`"auto" # or never`, "auto", true},
+		{`"a # b"`, "a # b", true},
+		{`"say \"hi\""`, `say "hi"`, true},
+		{`"tab\there"`, "tab\there", true},
+		{`""`, "", true},
+		{`"open`, "", false},
+		{`"zh" en`, "", false},
+		{``, "", false},
+		{`# only a comment`, "", false},
+		{`"bad \q escape"`, "", false},
+	}
+	for _, tt := range tests {
+		got, err := parseTOMLValue(tt.value)
+		if !tt.ok {
+			if err == nil {
+				t.Errorf("parseTOMLValue(%s) = %q, want an error", tt.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseTOMLValue(%s): %v", tt.value, err)
+		} else if got != tt.want {
+			t.Errorf("parseTOMLValue(%s) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestParseConfigTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want map[string]string
+		ok   bool
+	}{
+		{"empty", "", map[string]string{}, true},
+		{"values and comments", "# settings\n\nlang = \"zh\"\n  tempo=1.25  # faster\ncountdown = 0\n",
+			map[string]string{"lang": "zh", "tempo": "1.25", "countdown": "0"}, true},
+		{"later line wins", "lang = \"en\"\nlang = \"zh\"\n", map[string]string{"lang": "zh"}, true},
+		{"no equals sign", "lang \"zh\"\n", nil, false},
+		{"missing value", "tempo =\n", nil, false},
+		{"unterminated string", "lang = \"zh\n", nil, false},
+	}
+	for _, tt := range tests {
+		got, err := parseConfigTOML("config.toml", []byte(tt.data))
+		if !tt.ok {
+			if err == nil {
+				t.Errorf("%s: parsed %v, want an error", tt.name, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for key, value := range tt.want {
+			if got[key] != value {
+				t.Errorf("%s: %s = %q, want %q", tt.name, key, got[key], value)
+			}
+		}
+	}
+}
+
+func TestSetConfigTOML(t *testing.T) {
+	lang, _ := findSetting("lang")
+	tempo, _ := findSetting("tempo")
+	tests := []struct {
+		name  string
+		data  string
+		s     setting
+		value string
+		want  string
+	}{
+		{"new file", "", lang, "zh", "lang = \"zh\"\n"},
+		{"replace keeps other lines", "# my settings\nlang = \"en\" # default\ncolor = \"never\"\n", lang, "zh",
+			"# my settings\nlang = \"zh\"\ncolor = \"never\"\n"},
+		{"append", "# my settings\ncolor = \"never\"\n", tempo, "1.5",
+			"# my settings\ncolor = \"never\"\ntempo = 1.5\n"},
+		{"append without final newline", "color = \"never\"", tempo, "2",
+			"color = \"never\"\ntempo = 2\n"},
+		{"quotes are escaped", "", lang, `a"b`, "lang = \"a\\\"b\"\n"},
+	}
+	for _, tt := range tests {
+		got := string(setConfigTOML([]byte(tt.data), tt.s, tt.value))
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// setConfigEnv points the config directory at a temporary one holding
+// the given config.toml and clears the setting environment variables
+func setConfigEnv(t *testing.T, toml string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	for _, s := range settings {
+		t.Setenv(s.env, "")
+		os.Unsetenv(s.env)
+	}
+	if toml == "" {
+		return
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "terminal-gym"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "terminal-gym", configTOML), []byte(toml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	setConfigEnv(t, "tempo = 1.5\ncountdown = 5\ncolor = \"never\"\n")
+	t.Setenv("TERMINAL_GYM_COUNTDOWN", "0")
+
+	c, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Tempo != 1.5 {
+		t.Errorf("tempo = %g, want 1.5 from the file", c.Tempo)
+	}
+	if c.Countdown != 0 {
+		t.Errorf("countdown = %d, want 0 from the environment", c.Countdown)
+	}
+	if c.Color != colorNever {
+		t.Errorf("color = %q, want %q from the file", c.Color, colorNever)
+	}
+	if c.Exercise != "" {
+		t.Errorf("exercise = %q, want the default", c.Exercise)
+	}
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	setConfigEnv(t, "")
+
+	c, err := LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := defaultConfig()
+	if c != want {
+		t.Errorf("got %+v, want the defaults %+v", c, want)
+	}
+}
+
+func TestLoadConfigInvalidValues(t *testing.T) {
+	setConfigEnv(t, "tempo = NaN\ncountdown = 4\nsize = 3\n")
+	t.Setenv("TERMINAL_GYM_COUNTDOWN", "soon")
+
+	c, err := LoadConfig()
+	if err == nil {
+		t.Fatal("LoadConfig succeeded, want errors for tempo, size and $TERMINAL_GYM_COUNTDOWN")
+	}
+	// Invalid values leave the previous one in place
+	if c.Tempo != 1 {
+		t.Errorf("tempo = %g, want the default 1", c.Tempo)
+	}
+	if c.Countdown != 4 {
+		t.Errorf("countdown = %d, want 4 from the file", c.Countdown)
+	}
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/harmonica"
+)
+
+// ExerciseDefinition describes an exercise declaratively so new exercises
+// can be added by dropping a JSON or YAML file into an exercises directory
+// instead of writing a Go type. Both formats use the JSON field names.
+//
+// Text fields (name, description, instructions, tips and the counter label)
+// are looked up through the Localizer first and used verbatim when no
+// translation exists, so a definition can either ship its own locale keys
+// or just use plain text.
+type ExerciseDefinition struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Category    string            `json:"category"`
+	Description string            `json:"description"`
+	Spring      SpringDefinition  `json:"spring"`
+	Phases      []PhaseDefinition `json:"phases"`
+	Frames      [][]string        `json:"frames"`
+	Tips        []string          `json:"tips"`
+	Counter     CounterDefinition `json:"counter"`
+	// Target is the default number of reps; 0 runs until the user exits
+	Target int `json:"target"`
+
+	// Path is the file the definition was loaded from
+	Path string `json:"-"`
+}
+
+// PhaseDefinition is one step of an exercise cycle
+type PhaseDefinition struct {
+	Name string `json:"name"`
+	// Duration of the phase in seconds
+	Duration float64 `json:"duration"`
+	// Target spring position from -1 (first frame) to 1 (last frame)
+	Target float64 `json:"target"`
+	// Instruction shown above the animation during this phase
+	Instruction string `json:"instruction"`
+}
+
+// duration returns the phase length as a time.Duration
+func (p PhaseDefinition) duration() time.Duration {
+	return time.Duration(p.Duration * float64(time.Second))
+}
+
+// SpringDefinition tunes the harmonica spring driving the keyframes
+type SpringDefinition struct {
+	Frequency float64 `json:"frequency"`
+	Damping   float64 `json:"damping"`
+}
+
+// CounterDefinition controls what the exercise counts and how it is shown
+type CounterDefinition struct {
+	// Label is a format string (or locale key) with a single %d verb
+	Label string `json:"label"`
+	// Phase whose completion counts as one rep; defaults to the last phase
+	Phase string `json:"phase"`
+	// Start is added to the count when displayed, e.g. 1 to show the rep
+	// in progress rather than the number of completed reps
+	Start int `json:"start"`
+}
+
+// Validate checks that the definition can be interpreted
+func (d *ExerciseDefinition) Validate() error {
+	if d.ID == "" {
+		return errors.New("missing id")
+	}
+	if d.Name == "" {
+		return errors.New("missing name")
+	}
+	if len(d.Phases) == 0 {
+		return errors.New("at least one phase is required")
+	}
+	if len(d.Frames) == 0 {
+		return errors.New("at least one frame is required")
+	}
+	for i, phase := range d.Phases {
+		// The converted length is checked, as tiny or huge numbers come out
+		// as zero or negative
+		if phase.duration() <= 0 {
+			return fmt.Errorf("phase %d (%s): duration must be positive", i+1, phase.Name)
+		}
+		if phase.Target < -1 || phase.Target > 1 {
+			return fmt.Errorf("phase %d (%s): target must be between -1 and 1", i+1, phase.Name)
+		}
+	}
+	if d.Target < 0 {
+		return errors.New("target must not be negative")
+	}
+	if d.Counter.Phase != "" && d.counterPhase() < 0 {
+		return fmt.Errorf("counter phase %q does not exist", d.Counter.Phase)
+	}
+	return checkCounterLabel(d.Counter.Label)
+}
+
+// checkCounterLabel checks that a counter label, or the message of the
+// locale key it names, formats the count with a single %d
+func checkCounterLabel(label string) error {
+	if label == "" {
+		return nil
+	}
+	template := label
+	if translations, _ := loadLocale(referenceLocale); translations[label] != "" {
+		template = translations[label]
+	}
+	if !slices.Equal(formatVerbs(template), []string{"%d"}) {
+		return fmt.Errorf("counter label %q must be a locale key or a format with a single %%d", label)
+	}
+	return nil
+}
+
+// counterPhase returns the index of the phase that completes a rep
+func (d *ExerciseDefinition) counterPhase() int {
+	if d.Counter.Phase == "" {
+		return len(d.Phases) - 1
+	}
+	for i, phase := range d.Phases {
+		if phase.Name == d.Counter.Phase {
+			return i
+		}
+	}
+	return -1
+}
+
+// embeddedExercises holds the shipped definitions
+//
+//go:embed exercises/*.json
+var embeddedExercises embed.FS
+
+// LoadExerciseDefinition reads and validates a single definition file
+func LoadExerciseDefinition(filename string) (*ExerciseDefinition, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exercise definition %s: %w", filename, err)
+	}
+	return parseExerciseDefinition(filename, data)
+}
+
+// loadEmbeddedDefinition reads and validates a shipped definition
+func loadEmbeddedDefinition(name string) (*ExerciseDefinition, error) {
+	data, err := embeddedExercises.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exercise definition %s: %w", name, err)
+	}
+	return parseExerciseDefinition(name, data)
+}
+
+func parseExerciseDefinition(filename string, data []byte) (*ExerciseDefinition, error) {
+	def := &ExerciseDefinition{}
+	if err := decodeDataFile(filename, data, def); err != nil {
+		return nil, fmt.Errorf("failed to parse exercise definition %s: %w", filename, err)
+	}
+	def.ID = normalizeID(def.ID)
+	if err := def.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid exercise definition %s: %w", filename, err)
+	}
+	def.Path = filename
+
+	return def, nil
+}
+
+// LoadExerciseDefinitions loads every JSON and YAML definition from the given
+// directories, then the shipped ones. Missing directories are skipped and
+// files on disk override shipped definitions with the same ID; see
+// loadFirstByID.
+func LoadExerciseDefinitions(dirs ...string) ([]*ExerciseDefinition, error) {
+	return loadFirstByID(nil, func(def *ExerciseDefinition) string { return def.ID },
+		dirs, LoadExerciseDefinition, embeddedExercises, exercisesDir, loadEmbeddedDefinition)
+}
+
+// exerciseDirs returns the directories searched for exercise definitions
+func exerciseDirs() []string {
+	dirs := []string{exercisesDir}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, "terminal-gym", "exercises"))
+	}
+	return dirs
+}
+
+// DefinedExercise is a generic Exercise driven by an ExerciseDefinition
+type DefinedExercise struct {
+	Definition *ExerciseDefinition
+	Count      int
+	Target     int
+	FrameCount int64
+	Localizer  *Localizer
+
+	spring   harmonica.Spring
+	position float64
+	velocity float64
+	physics  fixedStep
+
+	phase        int
+	phaseElapsed time.Duration
+}
+
+func NewDefinedExercise(def *ExerciseDefinition, localizer *Localizer) *DefinedExercise {
+	freq := def.Spring.Frequency
+	if freq <= 0 {
+		freq = angularFreq
+	}
+	damping := def.Spring.Damping
+	if damping <= 0 {
+		damping = dampingRatio
+	}
+
+	return &DefinedExercise{
+		Definition: def,
+		Target:     def.Target,
+		Localizer:  localizer,
+		spring:     harmonica.NewSpring(harmonica.FPS(fps), freq, damping),
+	}
+}
+
+func (de *DefinedExercise) GetName() string {
+	return de.Localizer.T(de.Definition.Name)
+}
+
+func (de *DefinedExercise) GetCategory() string {
+	return categoryName(de.Localizer, de.Definition.Category)
+}
+
+func (de *DefinedExercise) GetDescription() string {
+	return de.Localizer.T(de.Definition.Description)
+}
+
+func (de *DefinedExercise) Render(w io.Writer) {
+	frames := de.Definition.Frames
+
+	normalizedPos := (de.position + animationRange) / (2 * animationRange)
+	if normalizedPos < 0 {
+		normalizedPos = 0
+	}
+	if normalizedPos > 1 {
+		normalizedPos = 1
+	}
+
+	stateIndex := int(normalizedPos * float64(len(frames)-1))
+	if stateIndex >= len(frames) {
+		stateIndex = len(frames) - 1
+	}
+
+	// Keep narrower frames centered on the widest one
+	padding := strings.Repeat(" ", (de.ArtWidth()-frameWidth(frames[stateIndex]))/2)
+	for _, line := range frames[stateIndex] {
+		fmt.Fprintf(w, "%s%s\n", padding, line)
+	}
+}
+
+func (de *DefinedExercise) ArtWidth() int {
+	return artWidth(de.Definition.Frames)
+}
+
+func (de *DefinedExercise) Update(dt time.Duration) {
+	phases := de.Definition.Phases
+
+	// Phases follow real elapsed time; overshoot carries into the next
+	// one. Validate rejects phases without length, which would never end.
+	de.phaseElapsed += dt
+	for phases[de.phase].duration() > 0 && de.phaseElapsed >= phases[de.phase].duration() {
+		de.phaseElapsed -= phases[de.phase].duration()
+		if de.phase == de.Definition.counterPhase() {
+			de.Count++
+		}
+		de.phase = (de.phase + 1) % len(phases)
+	}
+
+	target := phases[de.phase].Target * animationRange
+	for steps := de.physics.Steps(dt); steps > 0; steps-- {
+		de.FrameCount++
+		de.position, de.velocity = de.spring.Update(de.position, de.velocity, target)
+	}
+}
+
+func (de *DefinedExercise) SkipPhase() {
+	de.phaseElapsed = de.Definition.Phases[de.phase].duration()
+	de.Update(0)
+}
+
+func (de *DefinedExercise) GetInstructions() string {
+	return de.Localizer.T(de.Definition.Phases[de.phase].Instruction)
+}
+
+func (de *DefinedExercise) GetTips() []string {
+	tips := make([]string, 0, len(de.Definition.Tips)+1)
+	for _, key := range de.Definition.Tips {
+		tip := de.Localizer.T(key)
+		if !strings.HasPrefix(strings.TrimSpace(tip), "•") {
+			tip = "   • " + tip
+		}
+		tips = append(tips, tip)
+	}
+	return append(tips, de.Localizer.T("tip_exit"))
+}
+
+func (de *DefinedExercise) GetCounter() string {
+	label := de.Definition.Counter.Label
+	if label == "" {
+		label = "rep_counter"
+	}
+	return withTarget(de.Localizer.Tf(label, de.Count+de.Definition.Counter.Start), de.Target)
+}
+
+func (de *DefinedExercise) IsComplete() bool {
+	return de.Target > 0 && de.Count >= de.Target
+}
+
+func (de *DefinedExercise) SetTarget(target int) {
+	de.Target = target
+}
+
+func (de *DefinedExercise) GetTarget() int {
+	return de.Target
+}
+
+func (de *DefinedExercise) GetProgress() int {
+	return de.Count
+}
+
+func (de *DefinedExercise) Reset() {
+	de.Count = 0
+	de.FrameCount = 0
+	de.phase = 0
+	de.phaseElapsed = 0
+	de.position = de.Definition.Phases[0].Target * animationRange
+	de.velocity = 0.0
+	de.physics.Reset()
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const yamlDefinition = `# A calf raise in YAML
+id: CalfRaisesYAML
+name: Calf raises
+category: strength
+spring: { frequency: 5.0, damping: 0.6 }
+phases:
+  - name: rise
+    duration: 1.5
+    target: 1
+    instruction: Rise onto your toes
+  - { name: lower, duration: 2, target: -1, instruction: Lower slowly }
+frames:
+  - ["  ||  ", "__||__"]
+  - ["  ||  ", "  /\\  "]
+tips: [Hold a wall for balance]
+counter: { label: "Rep %d", phase: lower, start: 1 }
+target: 12
+`
+
+func writeDefinition(t *testing.T, dir, name, data string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadYAMLDefinition(t *testing.T) {
+	path := writeDefinition(t, t.TempDir(), "calf.yaml", yamlDefinition)
+	def, err := LoadExerciseDefinition(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shipped, err := LoadExerciseDefinition(filepath.Join("exercises", "calf_raises.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.ID != "calfraisesyaml" {
+		t.Errorf("id = %q, want it lowercased to calfraisesyaml", def.ID)
+	}
+	if len(def.Phases) != 2 || def.Phases[0] != (PhaseDefinition{"rise", 1.5, 1, "Rise onto your toes"}) ||
+		def.Phases[1].Duration != 2 || def.Phases[1].Target != -1 {
+		t.Errorf("phases = %+v", def.Phases)
+	}
+	if len(def.Frames) != 2 || def.Frames[1][1] != `  /\  ` {
+		t.Errorf("frames = %q", def.Frames)
+	}
+	if def.Spring != shipped.Spring || def.Target != 12 || def.Counter != (CounterDefinition{"Rep %d", "lower", 1}) {
+		t.Errorf("spring %+v, target %d, counter %+v", def.Spring, def.Target, def.Counter)
+	}
+	if !slices.Equal(def.Tips, []string{"Hold a wall for balance"}) {
+		t.Errorf("tips = %q", def.Tips)
+	}
+}
+
+func TestLoadInvalidYAMLDefinition(t *testing.T) {
+	dir := t.TempDir()
+	tests := map[string]string{
+		"syntax.yml":     "id: [unclosed\n",
+		"types.yml":      "id: x\nname: x\nphases: not a list\n",
+		"duration.yml":   "id: x\nname: x\nphases: [{name: a, duration: 0}]\nframes: [[a]]\n",
+		"non_string.yml": "id: x\n1: one\n",
+		"label.yml":      "id: x\nname: x\nphases: [{name: a, duration: 1}]\nframes: [[a]]\ncounter: {label: Holds}\n",
+		"two_verbs.yml":  "id: x\nname: x\nphases: [{name: a, duration: 1}]\nframes: [[a]]\ncounter: {label: \"%d of %d\"}\n",
+		"key_verbs.yml":  "id: x\nname: x\nphases: [{name: a, duration: 1}]\nframes: [[a]]\ncounter: {label: set_counter}\n",
+	}
+	for name, data := range tests {
+		if _, err := LoadExerciseDefinition(writeDefinition(t, dir, name, data)); err == nil {
+			t.Errorf("%s loaded, want an error", name)
+		}
+	}
+}
+
+func TestLoadExerciseDefinitionsFormats(t *testing.T) {
+	dir := t.TempDir()
+	writeDefinition(t, dir, "b.yaml", yamlDefinition)
+	writeDefinition(t, dir, "notes.txt", "not a definition")
+	data, err := os.ReadFile(filepath.Join("exercises", "calf_raises.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeDefinition(t, dir, "a.json", string(data))
+
+	files, err := dataFiles([]string{dir, filepath.Join(dir, "missing")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "a.json"), filepath.Join(dir, "b.yaml")}
+	if !slices.Equal(files, want) {
+		t.Errorf("definition files = %q, want %q", files, want)
+	}
+
+	defs, err := LoadExerciseDefinitions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, def := range defs {
+		ids = append(ids, def.ID)
+	}
+	// The file on disk replaces the shipped calf_raises
+	if want := []string{"calf_raises", "calfraisesyaml"}; !slices.Equal(ids, want) {
+		t.Errorf("ids = %q, want %q", ids, want)
+	}
+	if defs[0].Path != filepath.Join(dir, "a.json") {
+		t.Errorf("calf_raises loaded from %s, want the file on disk", defs[0].Path)
+	}
+}
+
+func TestMixedCaseDefinitionIsFound(t *testing.T) {
+	path := writeDefinition(t, t.TempDir(), "calf.yml", yamlDefinition)
+	def, err := LoadExerciseDefinition(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registered := exerciseRegistry
+	t.Cleanup(func() { exerciseRegistry = registered })
+	exerciseRegistry = slices.Clone(registered)
+	if err := RegisterDefinitions([]*ExerciseDefinition{def}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"CalfRaisesYAML", "calfraisesyaml", " CALFRAISESYAML "} {
+		if _, ok := FindExercise(id); !ok {
+			t.Errorf("FindExercise(%q) found nothing", id)
+		}
+	}
+}
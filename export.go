@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// exportFormats are the values of --format
+var exportFormats = []string{"csv", "json"}
+
+// exportColumns are the fields of an exported session, in order. Tools
+// importing the export rely on them, so only ever add columns at the end.
+var exportColumns = []string{"date", "exercise", "category", "duration", "reps", "cycles", "pattern", "language"}
+
+// exportRow is one session as exported. Duration is in whole seconds,
+// pauses excluded, so spreadsheets can sum it.
+type exportRow struct {
+	Date     string `json:"date"`
+	Exercise string `json:"exercise"`
+	Category string `json:"category"`
+	Duration int    `json:"duration"`
+	Reps     int    `json:"reps"`
+	Cycles   int    `json:"cycles"`
+	Pattern  string `json:"pattern"`
+	Language string `json:"language"`
+}
+
+func newExportRow(r SessionRecord) exportRow {
+	return exportRow{
+		Date:     r.Start.Local().Format(time.RFC3339),
+		Exercise: r.Exercise,
+		Category: r.Category,
+		Duration: int(r.Duration().Round(time.Second) / time.Second),
+		Reps:     r.Reps,
+		Cycles:   r.Cycles,
+		Pattern:  r.Pattern,
+		Language: r.Language,
+	}
+}
+
+// fields returns the row in the order of exportColumns
+func (row exportRow) fields() []string {
+	return []string{
+		row.Date,
+		row.Exercise,
+		row.Category,
+		strconv.Itoa(row.Duration),
+		strconv.Itoa(row.Reps),
+		strconv.Itoa(row.Cycles),
+		row.Pattern,
+		row.Language,
+	}
+}
+
+// exportCommand writes the completed sessions as CSV or JSON for other tools
+func exportCommand(localizer *Localizer, args []string) error {
+	fs := newFlagSet("export", localizer)
+	format := fs.String("format", "csv", localizer.T("flag_format"))
+	since := fs.String("since", "", localizer.T("flag_since"))
+	fs.Parse(args)
+
+	if !slices.Contains(exportFormats, *format) {
+		return fmt.Errorf("%s %s", localizer.T("export_error"), localizer.Tf("flag_choice", "format", strings.Join(exportFormats, ", ")))
+	}
+	var from time.Time
+	if *since != "" {
+		var err error
+		from, err = time.ParseInLocation(dayLayout, *since, time.Local)
+		if err != nil {
+			return fmt.Errorf("%s %s", localizer.T("export_error"), localizer.Tf("flag_date", "since", "2026-01-01"))
+		}
+	}
+
+	records, err := readHistory(localizer)
+	if err != nil {
+		return err
+	}
+	rows := exportRows(records, from)
+
+	if *format == "json" {
+		return writeExportJSON(os.Stdout, rows)
+	}
+	return writeExportCSV(os.Stdout, rows)
+}
+
+// exportRows converts the completed sessions started at or after from.
+// Aborted sessions are left out so they don't count as sessions.
+func exportRows(records []SessionRecord, from time.Time) []exportRow {
+	var rows []exportRow
+	for _, r := range records {
+		if r.Completed && !r.Start.Before(from) {
+			rows = append(rows, newExportRow(r))
+		}
+	}
+	return rows
+}
+
+// writeExportCSV writes a header line followed by one line per session
+func writeExportCSV(w io.Writer, rows []exportRow) error {
+	out := csv.NewWriter(w)
+	out.Write(exportColumns)
+	for _, row := range rows {
+		out.Write(row.fields())
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// writeExportJSON writes the sessions as a JSON array, empty if there are
+// none
+func writeExportJSON(w io.Writer, rows []exportRow) error {
+	if rows == nil {
+		rows = []exportRow{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(rows)
+}
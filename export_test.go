@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// exportRecords are a breathing session, a strength session with a pause
+// and an aborted session, in that order
+func exportRecords() []SessionRecord {
+	meditation := session("2026-02-27", 7, 4, 0)
+	meditation.Exercise = "meditation"
+	meditation.Category = categoryMeditation
+	meditation.Cycles = 3
+	meditation.Pattern = "4-7-8"
+	meditation.Language = "zh"
+	meditation.Completed = true
+
+	buttock := session("2026-03-02", 8, 5, 15)
+	buttock.Category = categoryStrength
+	buttock.Paused = jsonDuration(30*time.Second + 400*time.Millisecond)
+	buttock.Language = "en"
+	buttock.Completed = true
+
+	aborted := session("2026-03-03", 8, 0, 1)
+	aborted.End = aborted.Start.Add(time.Second)
+	aborted.Category = categoryStrength
+
+	return []SessionRecord{meditation, buttock, aborted}
+}
+
+func TestExportRows(t *testing.T) {
+	records := exportRecords()
+
+	rows := exportRows(records, time.Time{})
+	if len(rows) != 2 {
+		t.Fatalf("exported %d rows, want the 2 completed sessions", len(rows))
+	}
+	want := exportRow{
+		Date:     records[1].Start.Format(time.RFC3339),
+		Exercise: "buttock",
+		Category: categoryStrength,
+		Duration: 270,
+		Reps:     15,
+		Language: "en",
+	}
+	if rows[1] != want {
+		t.Errorf("row = %+v, want %+v", rows[1], want)
+	}
+
+	since, _ := time.ParseInLocation(dayLayout, "2026-03-02", time.Local)
+	rows = exportRows(records, since)
+	if len(rows) != 1 || rows[0].Exercise != "buttock" {
+		t.Errorf("--since=2026-03-02 exported %+v, want only the buttock session", rows)
+	}
+	since = since.AddDate(0, 0, 1)
+	if rows := exportRows(records, since); rows != nil {
+		t.Errorf("--since=2026-03-03 exported %+v, want nothing", rows)
+	}
+}
+
+func TestWriteExportCSV(t *testing.T) {
+	records := exportRecords()
+	var buf bytes.Buffer
+	if err := writeExportCSV(&buf, exportRows(records, time.Time{})); err != nil {
+		t.Fatal(err)
+	}
+	want := "date,exercise,category,duration,reps,cycles,pattern,language\n" +
+		records[0].Start.Format(time.RFC3339) + ",meditation,meditation,240,0,3,4-7-8,zh\n" +
+		records[1].Start.Format(time.RFC3339) + ",buttock,strength,270,15,0,,en\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := writeExportCSV(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := "date,exercise,category,duration,reps,cycles,pattern,language\n"; buf.String() != want {
+		t.Errorf("without sessions got %q, want only the header", buf.String())
+	}
+}
+
+func TestWriteExportJSON(t *testing.T) {
+	records := exportRecords()
+	var buf bytes.Buffer
+	if err := writeExportJSON(&buf, exportRows(records, time.Time{})); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("exported %d sessions, want 2", len(got))
+	}
+	for _, column := range exportColumns {
+		if _, ok := got[0][column]; !ok {
+			t.Errorf("JSON session has no %q field", column)
+		}
+	}
+	if len(got[0]) != len(exportColumns) {
+		t.Errorf("JSON session has fields %v, want exactly %v", got[0], exportColumns)
+	}
+	if got[0]["cycles"] != 3.0 || got[0]["pattern"] != "4-7-8" {
+		t.Errorf("meditation session = %v", got[0])
+	}
+
+	buf.Reset()
+	if err := writeExportJSON(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("without sessions got %q, want an empty array", buf.String())
+	}
+}
+
+// captureOutput runs f with standard output and standard error sent to
+// files and returns what was written to each
+func captureOutput(t *testing.T, f func()) (stdout, stderr string) {
+	t.Helper()
+	outFile, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	errFile, err := os.CreateTemp(t.TempDir(), "stderr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, savedErr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = outFile, errFile
+	defer func() { os.Stdout, os.Stderr = saved, savedErr }()
+	f()
+
+	out, _ := os.ReadFile(outFile.Name())
+	errOut, _ := os.ReadFile(errFile.Name())
+	return string(out), string(errOut)
+}
+
+func TestExportCorruptHistory(t *testing.T) {
+	localizer := newTestLocalizer(t)
+	dataDir := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataDir)
+	path, err := historyPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range exportRecords() {
+		if err := AppendHistory(path, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("garbage\n")
+	f.Close()
+
+	for _, format := range exportFormats {
+		var exportErr error
+		stdout, stderr := captureOutput(t, func() {
+			exportErr = exportCommand(localizer, []string{"--format=" + format})
+		})
+		if exportErr != nil {
+			t.Fatalf("--format=%s: %v", format, exportErr)
+		}
+		if !strings.Contains(stderr, localizer.T("history_warning")) {
+			t.Errorf("--format=%s: stderr %q has no warning about the broken line", format, stderr)
+		}
+
+		var rows int
+		if format == "json" {
+			var sessions []exportRow
+			if err := json.Unmarshal([]byte(stdout), &sessions); err != nil {
+				t.Fatalf("--format=json output doesn't parse: %v\n%s", err, stdout)
+			}
+			rows = len(sessions)
+		} else {
+			records, err := csv.NewReader(strings.NewReader(stdout)).ReadAll()
+			if err != nil {
+				t.Fatalf("--format=csv output doesn't parse: %v\n%s", err, stdout)
+			}
+			if !slices.Equal(records[0], exportColumns) {
+				t.Errorf("--format=csv header = %q, want %q", records[0], exportColumns)
+			}
+			rows = len(records) - 1
+		}
+		if rows != 2 {
+			t.Errorf("--format=%s exported %d sessions, want 2", format, rows)
+		}
+	}
+}
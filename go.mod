@@ -2,4 +2,10 @@ module github.com/Dragonchu/terminal-gym
 
 go 1.24.2
 
-require github.com/charmbracelet/harmonica v0.2.0
+require (
+	github.com/charmbracelet/harmonica v0.2.0
+	golang.org/x/term v0.36.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.37.0 // indirect
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const historyFile = "history.jsonl"
+
+// SessionRecord is one finished session, stored as one line of JSON in
+// the history file
+type SessionRecord struct {
+	Exercise  string       `json:"exercise"`
+	Category  string       `json:"category,omitempty"`
+	Program   string       `json:"program,omitempty"`
+	Start     time.Time    `json:"start"`
+	End       time.Time    `json:"end"`
+	Reps      int          `json:"reps,omitempty"`
+	Cycles    int          `json:"cycles,omitempty"`
+	Sets      int          `json:"sets,omitempty"`
+	Pattern   string       `json:"pattern,omitempty"`
+	Language  string       `json:"language,omitempty"`
+	Paused    jsonDuration `json:"paused,omitempty"`
+	Completed bool         `json:"completed"`
+}
+
+// Duration returns the time spent exercising, pauses excluded
+func (r SessionRecord) Duration() time.Duration {
+	return max(r.End.Sub(r.Start)-time.Duration(r.Paused), 0)
+}
+
+// historyPath returns where sessions are recorded:
+// $XDG_DATA_HOME/terminal-gym/history.jsonl, defaulting to ~/.local/share
+func historyPath() (string, error) {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("finding the history directory: %w", err)
+		}
+		dataDir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataDir, "terminal-gym", historyFile), nil
+}
+
+// AppendHistory adds a session to the end of the history in path,
+// creating the file and its directory if needed. Each record is written
+// with a single call so an interrupted write can't split a line.
+func AppendHistory(path string, record SessionRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open history %s: %w", path, err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write history %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// LoadHistory reads every session recorded in path, oldest first. A
+// missing file is an empty history. Lines that can't be parsed are
+// reported together, after the readable ones have been returned.
+func LoadHistory(path string) ([]SessionRecord, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var records []SessionRecord
+	var errs []error
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record SessionRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			errs = append(errs, fmt.Errorf("%s:%d: %w", path, line, err))
+			continue
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to read history %s: %w", path, err))
+	}
+	return records, errors.Join(errs...)
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendAndLoadHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "terminal-gym", historyFile)
+
+	records, err := LoadHistory(path)
+	if err != nil || records != nil {
+		t.Fatalf("missing history = %v, %v; want no records and no error", records, err)
+	}
+
+	first := session("2026-03-02", 8, 5, 15)
+	first.Paused = jsonDuration(20 * time.Second)
+	first.Completed = true
+	second := session("2026-03-03", 9, 4, 0)
+	second.Exercise = "meditation"
+	second.Cycles = 4
+	second.Pattern = "4-7-8"
+	for _, r := range []SessionRecord{first, second} {
+		if err := AppendHistory(path, r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	records, err = LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("loaded %d records, want 2", len(records))
+	}
+	if got := records[0]; !got.Start.Equal(first.Start) || got.Duration() != 4*time.Minute+40*time.Second ||
+		got.Reps != 15 || !got.Completed {
+		t.Errorf("first record = %+v, want %+v", got, first)
+	}
+	if got := records[1]; got.Exercise != "meditation" || got.Cycles != 4 || got.Pattern != "4-7-8" || got.Completed {
+		t.Errorf("second record = %+v, want %+v", got, second)
+	}
+}
+
+func TestLoadHistorySkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), historyFile)
+	data := `{"exercise":"buttock","start":"2026-03-02T08:00:00Z","end":"2026-03-02T08:05:00Z","reps":15,"completed":true}
+
+not json
+{"exercise":"meditation","start":"2026-03-03T08:00:00Z","end":"2026-03-03T08:04:00Z","cycles":4,"completed":true}
+{"exercise":"buttock","start":
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := LoadHistory(path)
+	if len(records) != 2 || records[0].Reps != 15 || records[1].Cycles != 4 {
+		t.Errorf("loaded %+v, want the two readable records", records)
+	}
+	if err == nil {
+		t.Fatal("LoadHistory succeeded, want errors for lines 3 and 5")
+	}
+	for _, line := range []string{":3:", ":5:"} {
+		if !strings.Contains(err.Error(), line) {
+			t.Errorf("error %q doesn't mention line %s", err, line)
+		}
+	}
+}
+
+func TestRecordStepExcludesPreviewPause(t *testing.T) {
+	localizer := newTestLocalizer(t)
+	clock := &fixedClock{now: time.Date(2026, 3, 4, 8, 0, 0, 0, time.Local)}
+
+	gym := NewTerminalGym(localizer)
+	gym.setOutput(&bytes.Buffer{})
+	gym.layout = Layout{Width: 80, Height: 50}
+	gym.clock = clock
+	gym.historyPath = filepath.Join(t.TempDir(), historyFile)
+	if err := gym.chooseExercise("buttock"); err != nil {
+		t.Fatal(err)
+	}
+	gym.currentExercise.SetTarget(0)
+	gym.useExercise(1, 0)
+	gym.startStep(0)
+	gym.nextUpLeft = nextUpDuration
+
+	advance := func(dt time.Duration) {
+		clock.now = clock.now.Add(dt)
+		gym.tick(clock.now, dt)
+	}
+
+	// A pause during the preview, before the step starts, then the rest
+	// of the preview
+	gym.paused = true
+	advance(30 * time.Second)
+	gym.paused = false
+	advance(nextUpDuration)
+
+	// 10s of exercise with a 3s pause in the middle
+	advance(5 * time.Second)
+	gym.paused = true
+	advance(3 * time.Second)
+	gym.paused = false
+	advance(5 * time.Second)
+	gym.recordStep(clock.now)
+
+	records, err := LoadHistory(gym.historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("recorded %d sessions, want 1", len(records))
+	}
+	if got := records[0]; got.Duration() != 10*time.Second || time.Duration(got.Paused) != 3*time.Second {
+		t.Errorf("recorded %v with %v paused, want 10s with 3s paused", got.Duration(), time.Duration(got.Paused))
+	}
+}
+
+func TestRecordStepUsesRegisteredID(t *testing.T) {
+	localizer := newTestLocalizer(t)
+	clock := &fixedClock{now: time.Date(2026, 3, 4, 8, 0, 0, 0, time.Local)}
+
+	newGym := func() *TerminalGym {
+		gym := NewTerminalGym(localizer)
+		gym.setOutput(&bytes.Buffer{})
+		gym.clock = clock
+		gym.historyPath = filepath.Join(t.TempDir(), historyFile)
+		return gym
+	}
+
+	// --exercise=Meditation
+	gym := newGym()
+	if err := gym.chooseExercise("Meditation"); err != nil {
+		t.Fatal(err)
+	}
+	gym.useExercise(1, 0)
+	gym.startStep(0)
+	clock.now = clock.now.Add(time.Minute)
+	gym.recordStep(clock.now)
+
+	// A program step naming " BUTTOCK "
+	programGym := newGym()
+	err := programGym.useProgram(&Program{ID: "test", Name: "test", Steps: []ProgramStep{{Exercise: " BUTTOCK "}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	programGym.startStep(0)
+	clock.now = clock.now.Add(time.Minute)
+	programGym.recordStep(clock.now)
+
+	for g, want := range map[*TerminalGym]string{gym: "meditation", programGym: "buttock"} {
+		records, err := LoadHistory(g.historyPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(records) != 1 || records[0].Exercise != want {
+			t.Errorf("recorded %+v, want one %s session", records, want)
+		}
+	}
+}
@@ -1,68 +1,251 @@
 package main
 
 import (
+	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 )
 
-// Localizer handles internationalization
+// embeddedLocales holds the shipped translations, so the binary works
+// from any directory
+//
+//go:embed locales/*.json
+var embeddedLocales embed.FS
+
+// localeDebugEnv turns on the locale debug mode, which marks every
+// translated string with the locale that served it
+const localeDebugEnv = "TERMINAL_GYM_LOCALE_DEBUG"
+
+// Localizer handles internationalization. Each key is looked up along a
+// fallback chain, e.g. zh-TW, then zh, then English.
 type Localizer struct {
-	translations map[string]string
+	chain        []string
+	translations map[string]map[string]string
 	language     string
+	debug        bool
 }
 
-// NewLocalizer creates a new localizer with the specified language
+// NewLocalizer creates a new localizer with the specified language. The
+// localizer is always usable: an error only reports a user translation
+// file that could not be read, in which case the shipped translations are
+// used without it.
 func NewLocalizer(lang string) (*Localizer, error) {
 	l := &Localizer{
-		language: lang,
-		translations: make(map[string]string),
+		chain: fallbackChain(lang),
+		translations: make(map[string]map[string]string),
+		debug: os.Getenv(localeDebugEnv) != "",
 	}
 	
 	if err := l.loadTranslations(); err != nil {
-		return nil, fmt.Errorf("failed to load translations: %w", err)
+		return l, fmt.Errorf("failed to load translations: %w", err)
 	}
 	
 	return l, nil
 }
 
-// loadTranslations loads the translation file for the current language
+// normalizeLanguage turns a language tag or a locale name such as
+// "zh_TW.UTF-8" into the form of the locale files, e.g. "zh-TW"
+func normalizeLanguage(lang string) string {
+	lang, _, _ = strings.Cut(lang, ".")
+	lang, _, _ = strings.Cut(lang, "@")
+	parts := strings.FieldsFunc(lang, func(r rune) bool { return r == '_' || r == '-' })
+	for i, part := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 2:
+			// Region, e.g. TW
+			parts[i] = strings.ToUpper(part)
+		case len(part) == 4:
+			// Script, e.g. Hant
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// fallbackChain lists the locales a key is looked up in: the language,
+// the language with its subtags dropped one at a time, and English
+func fallbackChain(lang string) []string {
+	var chain []string
+	for tag := normalizeLanguage(lang); tag != ""; {
+		chain = append(chain, tag)
+		i := strings.LastIndex(tag, "-")
+		if i < 0 {
+			break
+		}
+		tag = tag[:i]
+	}
+	if !slices.Contains(chain, referenceLocale) {
+		chain = append(chain, referenceLocale)
+	}
+	return chain
+}
+
+// detectLanguage reads the language from the locale environment variables,
+// in the order the C library uses them. It returns "" when they are unset
+// or name the C locale.
+func detectLanguage() string {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+		if value == "C" || value == "POSIX" || strings.HasPrefix(value, "C.") {
+			return ""
+		}
+		return normalizeLanguage(value)
+	}
+	return ""
+}
+
+// localeOverrideDir returns where users can add translation files or
+// override single keys of the shipped ones
+func localeOverrideDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("finding the config directory: %w", err)
+	}
+	return filepath.Join(configDir, "terminal-gym", "locales"), nil
+}
+
+// loadTranslations loads every locale of the fallback chain: the shipped
+// translations with the keys of the user's file merged on top. Locales
+// with neither are left out of the chain. The language is the first
+// locale that is left.
 func (l *Localizer) loadTranslations() error {
-	filename := filepath.Join("locales", l.language+".json")
-	
-	// Check if the file exists
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		// Fallback to English if the language file doesn't exist
-		l.language = "en"
-		filename = filepath.Join("locales", "en.json")
+	var errs []error
+	var chain []string
+	for _, lang := range l.chain {
+		translations, err := loadLocale(lang)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if translations != nil {
+			l.translations[lang] = translations
+			chain = append(chain, lang)
+		}
+	}
+	l.chain = chain
+	if len(chain) > 0 {
+		l.language = chain[0]
+	}
+	return errors.Join(errs...)
+}
+
+// loadLocale reads the shipped and the user's translations for lang and
+// merges them. It returns nil if there are neither.
+func loadLocale(lang string) (map[string]string, error) {
+	var translations map[string]string
+	if embedded, err := embeddedLocales.ReadFile("locales/" + lang + ".json"); err == nil {
+		if err := json.Unmarshal(embedded, &translations); err != nil {
+			return nil, fmt.Errorf("failed to parse translation file %s: %w", lang+".json", err)
+		}
 	}
 	
-	data, err := os.ReadFile(filename)
+	user, userPath, err := readUserLocale(lang)
+	if err != nil || user == nil {
+		return translations, err
+	}
+	// Unmarshaling into a copy of the shipped keys puts the user's on
+	// top, and leaves the shipped ones alone if the file is broken
+	merged := make(map[string]string, len(translations))
+	for key, value := range translations {
+		merged[key] = value
+	}
+	if err := json.Unmarshal(user, &merged); err != nil {
+		return translations, fmt.Errorf("failed to parse translation file %s: %w", userPath, err)
+	}
+	return merged, nil
+}
+
+// readUserLocale reads the user's translation file for lang. A missing
+// file or config directory returns no data and no error.
+func readUserLocale(lang string) ([]byte, string, error) {
+	dir, err := localeOverrideDir()
 	if err != nil {
-		return fmt.Errorf("failed to read translation file %s: %w", filename, err)
+		return nil, "", nil
 	}
-	
-	if err := json.Unmarshal(data, &l.translations); err != nil {
-		return fmt.Errorf("failed to parse translation file %s: %w", filename, err)
+	path := filepath.Join(dir, lang+".json")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, path, nil
 	}
-	
-	return nil
+	if err != nil {
+		return nil, path, fmt.Errorf("failed to read translation file %s: %w", path, err)
+	}
+	return data, path, nil
+}
+
+// lookup finds a key along the fallback chain and returns the locale
+// that has it
+func (l *Localizer) lookup(key string) (translation, locale string, ok bool) {
+	for _, lang := range l.chain {
+		if translation, ok := l.translations[lang][key]; ok {
+			return translation, lang, true
+		}
+	}
+	return "", "", false
 }
 
 // T translates a key to the current language
 func (l *Localizer) T(key string) string {
-	if translation, exists := l.translations[key]; exists {
-		return translation
+	translation, _ := l.translate(key)
+	return translation
+}
+
+// translate returns the translation of a key, marked with its locale in
+// debug mode, and the locale that served it
+func (l *Localizer) translate(key string) (translation, locale string) {
+	translation, locale, ok := l.lookup(key)
+	marker := locale
+	if !ok {
+		// Return the key itself if translation is not found
+		translation, locale, marker = key, l.language, "?"
+	}
+	if l.debug {
+		translation = "[" + marker + "]" + translation
 	}
-	// Return the key itself if translation is not found
-	return key
+	return translation, locale
 }
 
-// Tf translates a key with formatting
+// Tf translates a key with formatting. Templates with fmt verbs such as
+// "Rep: %d" are filled in by fmt.Sprintf; a translation may instead use
+// the message syntax of Tn, with the arguments named 0, 1, ... in order.
 func (l *Localizer) Tf(key string, args ...interface{}) string {
-	template := l.T(key)
-	return fmt.Sprintf(template, args...)
+	template, locale := l.translate(key)
+	if !isMessageFormat(template) {
+		return fmt.Sprintf(template, args...)
+	}
+	named := make(Args, len(args))
+	for i, arg := range args {
+		named[strconv.Itoa(i)] = arg
+	}
+	return l.format(template, locale, named)
+}
+
+// Tn translates a key whose message takes named arguments, such as
+// "{count, plural, one {# rep} other {# reps}}"
+func (l *Localizer) Tn(key string, args Args) string {
+	template, locale := l.translate(key)
+	return l.format(template, locale, args)
+}
+
+// format fills in a message with the plural rules of the locale that
+// served it, so an English fallback gets English plurals. A broken
+// message is shown as it is, so the text is still readable.
+func (l *Localizer) format(template, locale string, args Args) string {
+	text, err := formatMessage(template, locale, args)
+	if err != nil {
+		return template
+	}
+	return text
 }
 
 // GetLanguage returns the current language
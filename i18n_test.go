@@ -0,0 +1,87 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := []struct{ lang, want string }{
+		{"zh", "zh"},
+		{"en_US", "en-US"},
+		{"zh_TW.UTF-8", "zh-TW"},
+		{"sr@latin", "sr"},
+		{"sr_RS.UTF-8@latin", "sr-RS"},
+		{"zh-Hant-TW", "zh-Hant-TW"},
+		{"ZH_hant_tw", "zh-Hant-TW"},
+		{"DE", "de"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeLanguage(tt.lang); got != tt.want {
+			t.Errorf("normalizeLanguage(%q) = %q, want %q", tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestFallbackChain(t *testing.T) {
+	tests := []struct {
+		lang string
+		want []string
+	}{
+		{"zh_TW.UTF-8", []string{"zh-TW", "zh", "en"}},
+		{"zh-Hant-TW", []string{"zh-Hant-TW", "zh-Hant", "zh", "en"}},
+		{"en-GB", []string{"en-GB", "en"}},
+		{"en", []string{"en"}},
+		{"", []string{"en"}},
+	}
+	for _, tt := range tests {
+		if got := fallbackChain(tt.lang); !slices.Equal(got, tt.want) {
+			t.Errorf("fallbackChain(%q) = %v, want %v", tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name                    string
+		lcAll, lcMessages, lang string
+		want                    string
+	}{
+		{"nothing set", "", "", "", ""},
+		{"LANG", "", "", "zh_TW.UTF-8", "zh-TW"},
+		{"LC_MESSAGES before LANG", "", "zh_CN.UTF-8", "en_US.UTF-8", "zh-CN"},
+		{"LC_ALL before the others", "de_DE.UTF-8", "zh_CN.UTF-8", "en_US.UTF-8", "de-DE"},
+		{"C locale", "", "", "C", ""},
+		{"C.UTF-8", "", "", "C.UTF-8", ""},
+		{"POSIX", "", "", "POSIX", ""},
+		{"C in LC_ALL hides LANG", "C", "", "zh_CN.UTF-8", ""},
+		{"modifier", "", "", "sr_RS@latin", "sr-RS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LC_ALL", tt.lcAll)
+			t.Setenv("LC_MESSAGES", tt.lcMessages)
+			t.Setenv("LANG", tt.lang)
+			if got := detectLanguage(); got != tt.want {
+				t.Errorf("detectLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalizerDropsMissingLocales(t *testing.T) {
+	// Keep translation files of the user out of the chain
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	localizer, err := NewLocalizer("zh_TW.UTF-8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"zh", "en"}; !slices.Equal(localizer.chain, want) {
+		t.Errorf("chain = %v, want %v", localizer.chain, want)
+	}
+	if got := localizer.GetLanguage(); got != "zh" {
+		t.Errorf("language = %q, want zh", got)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Keys understood during a session
+const (
+	keyPause   = ' '
+	keyFaster  = '+'
+	keyFaster2 = '=' // + without shift
+	keySlower  = '-'
+	keySkip    = 'n'
+	keyRestart = 'r'
+	keyQuit    = 'q'
+	keyHelp    = '?'
+	keyCtrlC   = 3 // raw mode delivers Ctrl+C as a byte instead of SIGINT
+)
+
+// Keys used by the menu
+const (
+	keyEnter     = '\r' // raw mode
+	keyNewline   = '\n' // piped input
+	keyBackspace = 127
+	keyCtrlH     = 8
+	keyFilter    = '/'
+	keyDownJ     = 'j'
+	keyUpK       = 'k'
+)
+
+// escapeByte starts the sequences terminals send for arrow keys
+const escapeByte = 0x1b
+
+// Special keys decoded from escape sequences. They are negative so they
+// can't collide with typed characters.
+const (
+	keyUp rune = -(iota + 1)
+	keyDown
+	keyRight
+	keyLeft
+	keyEscape
+)
+
+// Tempo scales how fast exercise time passes
+const (
+	tempoStep = 0.25
+	minTempo  = 0.5
+	maxTempo  = 2.0
+)
+
+// makeRaw switches the terminal behind in to raw mode so keys arrive as
+// they are pressed, without echo. The returned function restores the
+// previous mode; it does nothing when in is not a terminal.
+func makeRaw(in io.Reader) func() {
+	if !isTerminal(in) {
+		return func() {}
+	}
+	f := in.(*os.File)
+	state, err := term.MakeRaw(int(f.Fd()))
+	if err != nil {
+		return func() {}
+	}
+	return func() {
+		term.Restore(int(f.Fd()), state)
+	}
+}
+
+// isTerminal reports whether in is a terminal rather than a pipe or file
+func isTerminal(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// readKeys sends every key read from r to keys until r fails, then
+// closes keys
+func readKeys(r *bufio.Reader, keys chan<- rune) {
+	defer close(keys)
+	for {
+		key, _, err := r.ReadRune()
+		if err != nil {
+			return
+		}
+		if key == escapeByte {
+			key = readEscape(r)
+		}
+		keys <- key
+	}
+}
+
+// readEscape decodes the rest of an escape sequence such as "\033[A".
+// A lone escape, with nothing following it in the buffer, is the Esc key.
+func readEscape(r *bufio.Reader) rune {
+	if r.Buffered() == 0 {
+		return keyEscape
+	}
+	if b, _ := r.ReadByte(); b != '[' && b != 'O' {
+		return keyEscape
+	}
+
+	// Skip parameters such as the "1;5" in "\033[1;5A"
+	for r.Buffered() > 0 {
+		b, _ := r.ReadByte()
+		switch b {
+		case 'A':
+			return keyUp
+		case 'B':
+			return keyDown
+		case 'C':
+			return keyRight
+		case 'D':
+			return keyLeft
+		}
+		if (b < '0' || b > '9') && b != ';' {
+			break
+		}
+	}
+	return keyEscape
+}
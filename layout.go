@@ -0,0 +1,99 @@
+package main
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const (
+	// maxColumnWidth caps rules and the text column on wide terminals
+	maxColumnWidth = 60
+
+	// Size assumed when the output is not a terminal and $COLUMNS/$LINES
+	// are not set
+	defaultWidth  = 80
+	defaultHeight = 50
+)
+
+// Layout is the space available for drawing a frame. It is read from the
+// terminal at startup and again whenever the window is resized.
+type Layout struct {
+	Width  int
+	Height int
+}
+
+// terminalLayout returns the size of the terminal out writes to, falling
+// back to $COLUMNS/$LINES and then to a default size
+func terminalLayout(out io.Writer) Layout {
+	if f, ok := out.(*os.File); ok {
+		if width, height, err := term.GetSize(int(f.Fd())); err == nil && width > 0 && height > 0 {
+			return Layout{Width: width, Height: height}
+		}
+	}
+
+	layout := Layout{Width: defaultWidth, Height: defaultHeight}
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		layout.Width = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("LINES")); err == nil && n > 0 {
+		layout.Height = n
+	}
+	return layout
+}
+
+// column returns the width of the centered text column
+func (l Layout) column() int {
+	return min(l.Width, maxColumnWidth)
+}
+
+// margin returns the indentation of the centered text column
+func (l Layout) margin() string {
+	return strings.Repeat(" ", max((l.Width-l.column())/2, 0))
+}
+
+// center pads s so it is centered in the terminal
+func (l Layout) center(s string) string {
+	return l.indent(s, displayWidth(s))
+}
+
+// indent pads s so a block of the given width is centered in the terminal
+func (l Layout) indent(s string, width int) string {
+	return strings.Repeat(" ", max((l.Width-width)/2, 0)) + s
+}
+
+// rule returns a horizontal rule spanning the text column
+func (l Layout) rule(ch string) string {
+	return l.margin() + strings.Repeat(ch, l.column())
+}
+
+// block centers lines as one unit, keeping their left edges aligned
+func (l Layout) block(lines ...string) []string {
+	width := frameWidth(lines)
+	centered := make([]string, len(lines))
+	for i, line := range lines {
+		centered[i] = l.indent(line, width)
+	}
+	return centered
+}
+
+// frameWidth returns the width of the widest line
+func frameWidth(lines []string) int {
+	width := 0
+	for _, line := range lines {
+		width = max(width, displayWidth(line))
+	}
+	return width
+}
+
+// artWidth returns the width of the widest line across all frames
+func artWidth(frames [][]string) int {
+	width := 0
+	for _, frame := range frames {
+		width = max(width, frameWidth(frame))
+	}
+	return width
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// dataExtensions are the file types exercise definitions and programs are
+// read from
+var dataExtensions = []string{".json", ".yaml", ".yml"}
+
+// isDataFile reports whether name has one of the dataExtensions
+func isDataFile(name string) bool {
+	return slices.Contains(dataExtensions, filepath.Ext(name))
+}
+
+// normalizeID returns the form IDs of exercises and programs are stored and
+// looked up in, so "CalfRaises" and "calfraises" name the same one
+func normalizeID(id string) string {
+	return strings.ToLower(strings.TrimSpace(id))
+}
+
+// decodeDataFile decodes a JSON or YAML file, told apart by its extension,
+// into v. YAML is converted to JSON first so both formats use the same
+// field names and checks.
+func decodeDataFile(filename string, data []byte, v any) error {
+	if ext := filepath.Ext(filename); ext == ".yaml" || ext == ".yml" {
+		var value any
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		var err error
+		if data, err = json.Marshal(value); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(data, v)
+}
+
+// dataFiles lists the data files in dirs, sorted by name within each
+// directory; missing directories are skipped
+func dataFiles(dirs []string) ([]string, error) {
+	var files []string
+	var errs []error
+	for _, dir := range dirs {
+		var matches []string
+		for _, ext := range dataExtensions {
+			found, err := filepath.Glob(filepath.Join(dir, "*"+ext))
+			if err != nil {
+				errs = append(errs, err)
+			}
+			matches = append(matches, found...)
+		}
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files, errors.Join(errs...)
+}
+
+// embeddedFiles lists the data files of a directory embedded in the binary
+func embeddedFiles(fsys embed.FS, dir string) []string {
+	var files []string
+	for _, ext := range dataExtensions {
+		found, _ := fs.Glob(fsys, path.Join(dir, "*"+ext))
+		files = append(files, found...)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// loadFirstByID loads the data files in dirs, then the ones shipped in the
+// binary under embeddedDir, and appends them to known. The first one seen
+// for an ID wins, so files on disk override the shipped ones and neither
+// replaces anything in known. Files that fail to load are reported in the
+// returned error but do not prevent the others from loading.
+func loadFirstByID[T any](known []T, id func(T) string, dirs []string, load func(filename string) (T, error),
+	embedded embed.FS, embeddedDir string, loadEmbedded func(name string) (T, error)) ([]T, error) {
+	loaded := append([]T{}, known...)
+	var errs []error
+	seen := make(map[string]bool)
+	for _, item := range known {
+		seen[id(item)] = true
+	}
+	add := func(item T, err error) {
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		if !seen[id(item)] {
+			seen[id(item)] = true
+			loaded = append(loaded, item)
+		}
+	}
+
+	files, err := dataFiles(dirs)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	for _, file := range files {
+		add(load(file))
+	}
+	for _, file := range embeddedFiles(embedded, embeddedDir) {
+		add(loadEmbedded(file))
+	}
+	return loaded, errors.Join(errs...)
+}
@@ -2,11 +2,11 @@ package main
 
 import (
 	"bufio"
-	"flag"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
-	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -20,6 +20,14 @@ const (
 	angularFreq    = 4.0
 	dampingRatio   = 0.3
 	animationRange = 8.0
+	
+	// Columns the animations may drift sideways by
+	buttWobble   = 20
+	breathWobble = 15
+	
+	// Default targets used when no --reps flag is given
+	defaultButtockReps    = 15
+	defaultMeditationReps = 4
 )
 
 // Exercise interface
@@ -27,22 +35,36 @@ type Exercise interface {
 	GetName() string
 	GetCategory() string
 	GetDescription() string
-	Render() 
-	Update()
+	// Render draws the exercise animation into w, starting at column 0
+	Render(w io.Writer)
+	// ArtWidth returns the widest line Render can produce, including any
+	// room the animation moves around in, so the art can be centered
+	ArtWidth() int
+	// Update advances the exercise by the wall-clock time since the last call
+	Update(dt time.Duration)
+	// SkipPhase ends the current phase as if its time had run out
+	SkipPhase()
 	GetInstructions() string
 	GetTips() []string
 	IsComplete() bool
 	Reset()
 	GetCounter() string
+	// SetTarget sets the reps (or breath cycles) that complete the
+	// exercise; 0 means run until the user exits
+	SetTarget(target int)
+	GetTarget() int
+	// GetProgress returns the number of completed reps (or breath cycles)
+	GetProgress() int
 }
 
 // ButtockExercise represents the buttock lifting exercise
 type ButtockExercise struct {
-	// Base exercise properties
+	// Base exercise properties; Name and Description are locale keys
 	Name        string
 	Category    string
 	Description string
 	Cycle       int
+	Target      int
 	FrameCount  int64
 	Localizer   *Localizer
 	
@@ -74,6 +96,25 @@ type ButtockExercise struct {
 	tensionPosition float64
 	tensionVelocity float64
 	tensionTarget   float64
+	
+	// Steps the springs at a fixed rate regardless of frame timing
+	physics fixedStep
+}
+
+func init() {
+	registerBuiltin(&ExerciseInfo{
+		ID:       "buttock",
+		Category: categoryStrength,
+		New:      func(localizer *Localizer) Exercise { return NewButtockExercise(localizer) },
+	})
+}
+
+// registerBuiltin registers an exercise compiled into the gym
+func registerBuiltin(info *ExerciseInfo) {
+	info.Source = builtinSource
+	if err := RegisterExercise(info); err != nil {
+		panic(err)
+	}
 }
 
 // Enhanced ASCII art for different butt states with more detail
@@ -146,10 +187,11 @@ var buttStates = [][]string{
 
 func NewButtockExercise(localizer *Localizer) *ButtockExercise {
 	return &ButtockExercise{
-		Name:        "Buttock Lifting",
-		Category:    "Strength",
-		Description: "Buttock lifting exercise with animated guidance",
+		Name:        "buttock_name",
+		Category:    categoryStrength,
+		Description: "buttock_description",
 		Cycle:       0,
+		Target:      defaultButtockReps,
 		FrameCount:  0,
 		Localizer:   localizer,
 		
@@ -186,18 +228,18 @@ func NewButtockExercise(localizer *Localizer) *ButtockExercise {
 }
 
 func (be *ButtockExercise) GetName() string {
-	return be.Name
+	return be.Localizer.T(be.Name)
 }
 
 func (be *ButtockExercise) GetCategory() string {
-	return be.Category
+	return categoryName(be.Localizer, be.Category)
 }
 
 func (be *ButtockExercise) GetDescription() string {
-	return be.Description
+	return be.Localizer.T(be.Description)
 }
 
-func (be *ButtockExercise) renderButt() {
+func (be *ButtockExercise) renderButt(w io.Writer) {
 	// Calculate the base animation state using main spring
 	normalizedPos := (be.mainPosition + animationRange) / (2 * animationRange)
 	if normalizedPos < 0 {
@@ -230,17 +272,21 @@ func (be *ButtockExercise) renderButt() {
 	}
 	
 	// Dynamic padding based on breathing and asymmetry
-	basePadding := 15
+	basePadding := buttWobble / 2
 	dynamicPadding := basePadding + breathOffset + leftOffset - rightOffset
-	if dynamicPadding < 5 {
-		dynamicPadding = 5
+	if dynamicPadding < 0 {
+		dynamicPadding = 0
 	}
-	if dynamicPadding > 25 {
-		dynamicPadding = 25
+	if dynamicPadding > buttWobble {
+		dynamicPadding = buttWobble
 	}
 	
-	// Render each line of the butt with subtle modifications
+	// Keep narrower states centered on the widest one
 	buttLines := buttStates[baseStateIndex]
+	artWidth := artWidth(buttStates)
+	dynamicPadding += (artWidth - frameWidth(buttLines)) / 2
+	
+	// Render each line of the butt with subtle modifications
 	for _, line := range buttLines {
 		
 		// Apply tension-based character substitution for more defined look
@@ -271,24 +317,39 @@ func (be *ButtockExercise) renderButt() {
 			}
 		}
 		
-		fmt.Printf("%s%s%s\n", padding, line, rotationEffect)
+		fmt.Fprintf(w, "%s%s%s\n", padding, line, rotationEffect)
 	}
 	
-	// Add subtle muscle activation indicators
+	// Add subtle muscle activation indicators, centered under the art
+	indicator := ""
 	if tensionIntensity > 0.8 {
-		indicatorPadding := strings.Repeat(" ", dynamicPadding+8)
-		fmt.Printf("%s💪 Peak Activation 💪\n", indicatorPadding)
+		indicator = be.Localizer.T("peak_activation")
 	} else if tensionIntensity > 0.5 {
-		indicatorPadding := strings.Repeat(" ", dynamicPadding+10)
-		fmt.Printf("%s⚡ Engaged ⚡\n", indicatorPadding)
+		indicator = be.Localizer.T("engaged")
+	}
+	if indicator != "" {
+		indicatorPadding := strings.Repeat(" ", max(dynamicPadding+(frameWidth(buttLines)-displayWidth(indicator))/2, 0))
+		fmt.Fprintf(w, "%s%s\n", indicatorPadding, indicator)
 	}
 }
 
-func (be *ButtockExercise) Render() {
-	be.renderButt()
+// ArtWidth covers the widest state, the wobble room and the tilt indicator
+func (be *ButtockExercise) ArtWidth() int {
+	return buttWobble + artWidth(buttStates) + 2
 }
 
-func (be *ButtockExercise) Update() {
+func (be *ButtockExercise) Render(w io.Writer) {
+	be.renderButt(w)
+}
+
+func (be *ButtockExercise) Update(dt time.Duration) {
+	for steps := be.physics.Steps(dt); steps > 0; steps-- {
+		be.step()
+	}
+}
+
+// step advances the spring physics by one fixed physics step
+func (be *ButtockExercise) step() {
 	be.FrameCount++
 	
 	// Update main spring physics
@@ -312,12 +373,17 @@ func (be *ButtockExercise) Update() {
 	
 	// Check if we need to change target (cycle between contract and expand)
 	if be.hasReachedMainTarget() {
-		be.Cycle++
-		if be.Cycle%2 == 0 {
-			be.mainTarget = -animationRange // Contract
-		} else {
-			be.mainTarget = animationRange  // Expand
-		}
+		be.SkipPhase()
+	}
+}
+
+// SkipPhase switches between contracting and expanding
+func (be *ButtockExercise) SkipPhase() {
+	be.Cycle++
+	if be.Cycle%2 == 0 {
+		be.mainTarget = -animationRange // Contract
+	} else {
+		be.mainTarget = animationRange  // Expand
 	}
 }
 
@@ -348,11 +414,24 @@ func (be *ButtockExercise) GetTips() []string {
 }
 
 func (be *ButtockExercise) GetCounter() string {
-	return be.Localizer.Tf("rep_counter", be.Cycle/2+1)
+	return withTarget(be.Localizer.Tf("rep_counter", be.Cycle/2+1), be.Target)
 }
 
 func (be *ButtockExercise) IsComplete() bool {
-	return false // This exercise runs indefinitely until user exits
+	return be.Target > 0 && be.GetProgress() >= be.Target
+}
+
+func (be *ButtockExercise) SetTarget(target int) {
+	be.Target = target
+}
+
+func (be *ButtockExercise) GetTarget() int {
+	return be.Target
+}
+
+// GetProgress returns completed reps; each rep is a contract and an expand
+func (be *ButtockExercise) GetProgress() int {
+	return be.Cycle / 2
 }
 
 func (be *ButtockExercise) Reset() {
@@ -369,15 +448,25 @@ func (be *ButtockExercise) Reset() {
 	be.breathVelocity = 0.0
 	be.tensionPosition = 0.0
 	be.tensionVelocity = 0.0
+	be.physics.Reset()
+}
+
+func init() {
+	registerBuiltin(&ExerciseInfo{
+		ID:       "meditation",
+		Category: categoryMeditation,
+		New:      func(localizer *Localizer) Exercise { return NewMeditationExercise(localizer) },
+	})
 }
 
 // MeditationExercise represents a deep breathing meditation exercise
 type MeditationExercise struct {
-	// Base exercise properties
+	// Base exercise properties; Name and Description are locale keys
 	Name        string
 	Category    string
 	Description string
 	Cycle       int
+	Target      int
 	FrameCount  int64
 	Localizer   *Localizer
 	
@@ -400,11 +489,16 @@ type MeditationExercise struct {
 	heartTarget    float64
 	
 	// Meditation state
+	Pattern        *BreathingPattern
 	isInhaling     bool
 	breathCycles   int
-	phase          string // "inhale", "hold", "exhale", "pause"
-	phaseTimer     int
-	phaseDuration  int
+	phaseIndex     int
+	phase          string // one of the phase kinds, e.g. phaseInhale
+	phaseElapsed   time.Duration
+	phaseDuration  time.Duration
+	
+	// Steps the springs at a fixed rate regardless of frame timing
+	physics fixedStep
 }
 
 // ASCII art for different breathing states
@@ -481,11 +575,13 @@ var breathingStates = [][]string{
 }
 
 func NewMeditationExercise(localizer *Localizer) *MeditationExercise {
-	return &MeditationExercise{
-		Name:        "Deep Breathing Meditation",
-		Category:    "Meditation", 
-		Description: "Guided deep breathing exercise for relaxation and mindfulness",
+	pattern, _ := FindBreathingPattern(localizer, defaultPatternID)
+	me := &MeditationExercise{
+		Name:        "meditation_name",
+		Category:    categoryMeditation,
+		Description: "meditation_description",
 		Cycle:       0,
+		Target:      defaultMeditationReps,
 		FrameCount:  0,
 		Localizer:   localizer,
 		
@@ -507,27 +603,43 @@ func NewMeditationExercise(localizer *Localizer) *MeditationExercise {
 		heartVelocity: 0.0,
 		heartTarget:   0.0,
 		
+		Pattern:       pattern,
 		isInhaling:    true,
 		breathCycles:  0,
-		phase:         "inhale",
-		phaseTimer:    0,
-		phaseDuration: 120, // 4 seconds at 30fps
 	}
+	me.startPhase(0)
+	return me
+}
+
+// SetPattern switches to a different breathing pattern
+func (me *MeditationExercise) SetPattern(pattern *BreathingPattern) {
+	me.Pattern = pattern
+	me.startPhase(0)
+}
+
+// startPhase moves to the given phase of the breathing pattern
+func (me *MeditationExercise) startPhase(index int) {
+	current := me.Pattern.Phases[index]
+	me.phaseIndex = index
+	me.phase = current.Kind
+	me.phaseElapsed = 0
+	me.phaseDuration = current.duration()
+	me.isInhaling = current.Kind == phaseInhale || current.Kind == phaseSip
 }
 
 func (me *MeditationExercise) GetName() string {
-	return me.Name
+	return me.Localizer.T(me.Name)
 }
 
 func (me *MeditationExercise) GetCategory() string {
-	return me.Category
+	return categoryName(me.Localizer, me.Category)
 }
 
 func (me *MeditationExercise) GetDescription() string {
-	return me.Description
+	return me.Localizer.T(me.Description)
 }
 
-func (me *MeditationExercise) renderBreathing() {
+func (me *MeditationExercise) renderBreathing(w io.Writer) {
 	// Calculate the base animation state using breath spring
 	normalizedPos := (me.breathPosition + animationRange) / (2 * animationRange)
 	if normalizedPos < 0 {
@@ -550,88 +662,96 @@ func (me *MeditationExercise) renderBreathing() {
 	heartOffset := int(me.heartPosition * 0.1)
 	
 	// Dynamic padding for breathing effect
-	basePadding := 10
+	basePadding := breathWobble / 3
 	dynamicPadding := basePadding + lungOffset + heartOffset
-	if dynamicPadding < 5 {
-		dynamicPadding = 5
+	if dynamicPadding < 0 {
+		dynamicPadding = 0
 	}
-	if dynamicPadding > 20 {
-		dynamicPadding = 20
+	if dynamicPadding > breathWobble {
+		dynamicPadding = breathWobble
 	}
 	
-	// Render each line of the breathing animation
+	// Keep narrower states centered on the widest one
 	breathLines := breathingStates[baseStateIndex]
+	dynamicPadding += (artWidth(breathingStates) - frameWidth(breathLines)) / 2
+	
+	// Render each line of the breathing animation
 	for i, line := range breathLines {
 		padding := strings.Repeat(" ", dynamicPadding)
 		
-		// Add subtle heart beat effect to the heart symbol line
+		// Add subtle heart beat effect to the heart symbol line. The emoji
+		// hearts are two columns wide, so they take the space after ♡ too.
 		if strings.Contains(line, "♡") {
 			if me.heartPosition > 3.0 {
-				line = strings.ReplaceAll(line, "♡", "💖") // Stronger heart beat
+				line = strings.ReplaceAll(line, "♡ ", "💖") // Stronger heart beat
 			} else if me.heartPosition > 1.0 {
-				line = strings.ReplaceAll(line, "♡", "💗") // Medium heart beat
+				line = strings.ReplaceAll(line, "♡ ", "💗") // Medium heart beat
 			}
 		}
 		
 		// Add breathing indicators
-		if i == 0 && me.phase == "inhale" {
+		if i == 0 && (me.phase == phaseInhale || me.phase == phaseSip) {
 			line += "  ↑ " + me.Localizer.T("inhaling")
-		} else if i == 0 && me.phase == "exhale" {
+		} else if i == 0 && me.phase == phaseExhale {
 			line += "  ↓ " + me.Localizer.T("exhaling")
-		} else if i == 0 && me.phase == "hold" {
+		} else if i == 0 && me.phase == phaseHold {
 			line += "  ⏸ " + me.Localizer.T("holding")
-		} else if i == 0 && me.phase == "pause" {
+		} else if i == 0 && me.phase == phasePause {
 			line += "  ⏹ " + me.Localizer.T("pausing")
 		}
 		
-		fmt.Printf("%s%s\n", padding, line)
+		fmt.Fprintf(w, "%s%s\n", padding, line)
 	}
 }
 
-func (me *MeditationExercise) Render() {
-	me.renderBreathing()
+func (me *MeditationExercise) Render(w io.Writer) {
+	me.renderBreathing(w)
 }
 
-func (me *MeditationExercise) Update() {
-	me.FrameCount++
-	me.phaseTimer++
+// ArtWidth covers the widest state and the room the lungs move around in;
+// the phase label beside the art is allowed to overhang
+func (me *MeditationExercise) ArtWidth() int {
+	return breathWobble + artWidth(breathingStates)
+}
+
+func (me *MeditationExercise) Update(dt time.Duration) {
+	// Advance through the phases of the breathing pattern by real elapsed
+	// time, carrying any overshoot into the next phase. A phase without
+	// length would never be left, so it stops the catch-up instead.
+	me.phaseElapsed += dt
+	for me.phaseDuration > 0 && me.phaseElapsed >= me.phaseDuration {
+		overshoot := me.phaseElapsed - me.phaseDuration
+		if me.phaseIndex == me.Pattern.cycleEnd() {
+			me.breathCycles++
+		}
+		me.startPhase((me.phaseIndex + 1) % len(me.Pattern.Phases))
+		me.phaseElapsed = overshoot
+	}
 	
-	// Update breathing phases (4-7-8 breathing technique)
+	// Holds keep the lungs wherever the previous phase left them
 	switch me.phase {
-	case "inhale":
-		if me.phaseTimer >= 120 { // 4 seconds
-			me.phase = "hold"
-			me.phaseTimer = 0
-		}
+	case phaseInhale, phaseSip:
 		me.breathTarget = animationRange
 		me.lungTarget = animationRange * 0.8
-		
-	case "hold":
-		if me.phaseTimer >= 210 { // 7 seconds
-			me.phase = "exhale" 
-			me.phaseTimer = 0
-		}
-		me.breathTarget = animationRange
-		me.lungTarget = animationRange * 0.8
-		
-	case "exhale":
-		if me.phaseTimer >= 240 { // 8 seconds
-			me.phase = "pause"
-			me.phaseTimer = 0
-			me.breathCycles++
-		}
-		me.breathTarget = -animationRange
-		me.lungTarget = -animationRange * 0.6
-		
-	case "pause":
-		if me.phaseTimer >= 60 { // 2 seconds
-			me.phase = "inhale"
-			me.phaseTimer = 0
-		}
+	case phaseExhale:
 		me.breathTarget = -animationRange
 		me.lungTarget = -animationRange * 0.6
 	}
 	
+	for steps := me.physics.Steps(dt); steps > 0; steps-- {
+		me.step()
+	}
+}
+
+func (me *MeditationExercise) SkipPhase() {
+	me.phaseElapsed = me.phaseDuration
+	me.Update(0)
+}
+
+// step advances the spring physics by one fixed physics step
+func (me *MeditationExercise) step() {
+	me.FrameCount++
+	
 	// Update spring physics
 	me.breathPosition, me.breathVelocity = me.breathSpring.Update(me.breathPosition, me.breathVelocity, me.breathTarget)
 	me.lungPosition, me.lungVelocity = me.lungSpring.Update(me.lungPosition, me.lungVelocity, me.lungTarget)
@@ -643,36 +763,46 @@ func (me *MeditationExercise) Update() {
 
 func (me *MeditationExercise) GetInstructions() string {
 	switch me.phase {
-	case "inhale":
+	case phaseInhale:
 		return me.Localizer.T("breathe_in_instruction")
-	case "hold":
+	case phaseSip:
+		return me.Localizer.T("sip_instruction")
+	case phaseHold:
 		return me.Localizer.T("hold_breath_instruction")
-	case "exhale":
+	case phaseExhale:
 		return me.Localizer.T("breathe_out_instruction")
-	case "pause":
+	case phasePause:
 		return me.Localizer.T("pause_instruction")
 	}
 	return ""
 }
 
 func (me *MeditationExercise) GetTips() []string {
-	return []string{
-		me.Localizer.T("tip_breathe_478"),
-		me.Localizer.T("tip_inhale"),
-		me.Localizer.T("tip_hold"),
-		me.Localizer.T("tip_exhale"),
-		me.Localizer.T("tip_pause"),
+	tips := me.Pattern.Tips(me.Localizer)
+	return append(tips,
 		me.Localizer.T("tip_focus"),
 		me.Localizer.T("tip_exit"),
-	}
+	)
 }
 
 func (me *MeditationExercise) GetCounter() string {
-	return me.Localizer.Tf("breath_counter", me.breathCycles)
+	return withTarget(me.Localizer.Tf("breath_counter", me.breathCycles), me.Target)
 }
 
 func (me *MeditationExercise) IsComplete() bool {
-	return false // Meditation runs indefinitely until user exits
+	return me.Target > 0 && me.breathCycles >= me.Target
+}
+
+func (me *MeditationExercise) SetTarget(target int) {
+	me.Target = target
+}
+
+func (me *MeditationExercise) GetTarget() int {
+	return me.Target
+}
+
+func (me *MeditationExercise) GetProgress() int {
+	return me.breathCycles
 }
 
 func (me *MeditationExercise) Reset() {
@@ -687,132 +817,659 @@ func (me *MeditationExercise) Reset() {
 	me.heartPosition = 0.0
 	me.heartVelocity = 0.0
 	me.heartTarget = 0.0
-	me.isInhaling = true
 	me.breathCycles = 0
-	me.phase = "inhale"
-	me.phaseTimer = 0
-	me.phaseDuration = 120
+	me.startPhase(0)
+	me.physics.Reset()
 }
 
 // TerminalGym manages the overall application
 type TerminalGym struct {
 	currentExercise Exercise
+	exerciseID     string
+	workout        *SetRunner
 	localizer      *Localizer
+	screen         *Screen
+	
+	// Where the gym reads keys and writes everything it displays; key
+	// presses from in are shared by the menu and the session
+	in         io.Reader
+	keyPresses <-chan rune
+	out        io.Writer
+	
+	// Steps played in order; a single exercise is a one-step program
+	program    *Program
+	steps      []*SetRunner
+	stepIndex  int
+	nextUpLeft time.Duration
+	
+	// Drives all exercise timing; replaceable for deterministic runs
+	clock Clock
+	
+	// Size of the terminal, refreshed when the window is resized
+	layout Layout
+	
+	// Whether titles, the menu highlight and the pause line are colored
+	color bool
+	
+	// Keyboard controls
+	paused       bool
+	pausedFor    time.Duration
+	tempo        float64
+	showHelp     bool
+	restoreInput func()
+	
+	// Each step is appended to the history file once it ends; an empty
+	// path disables the history
+	historyPath   string
+	historyErr    error
+	stepStart     time.Time
+	stepPaused    time.Duration
+	recordedSteps int
+	
+	// What the summary reports: every recorded step, and the time spent
+	// exercising weighted by tempo
+	records      []SessionRecord
+	activeFor    time.Duration
+	tempoSeconds float64
 }
 
 func NewTerminalGym(localizer *Localizer) *TerminalGym {
 	return &TerminalGym{
-		localizer: localizer,
+		localizer:   localizer,
+		screen:      NewScreen(os.Stdout),
+		in:          os.Stdin,
+		out:         os.Stdout,
+		clock:       realClock{},
+		layout:      terminalLayout(os.Stdout),
+		tempo:       1,
+	}
+}
+
+// lookupExercise finds a registered exercise by ID, with an error listing
+// the registered IDs if there is none
+func (tg *TerminalGym) lookupExercise(id string) (*ExerciseInfo, error) {
+	info, ok := FindExercise(id)
+	if !ok {
+		return nil, errors.New(tg.localizer.Tf("exercise_unknown", id, strings.Join(exerciseIDs(), ", ")))
+	}
+	return info, nil
+}
+
+// newExercise creates a registered exercise from its ID
+func (tg *TerminalGym) newExercise(id string) (Exercise, error) {
+	info, err := tg.lookupExercise(id)
+	if err != nil {
+		return nil, err
+	}
+	return info.New(tg.localizer), nil
+}
+
+// chooseExercise makes the registered exercise with the given ID the one
+// useExercise plays. The ID is kept as registered, however it was typed.
+func (tg *TerminalGym) chooseExercise(id string) error {
+	info, err := tg.lookupExercise(id)
+	if err != nil {
+		return err
+	}
+	tg.currentExercise = info.New(tg.localizer)
+	tg.exerciseID = info.ID
+	return nil
+}
+
+// useExercise plays the selected exercise on its own
+func (tg *TerminalGym) useExercise(sets int, rest time.Duration) {
+	runner := NewSetRunner(tg.currentExercise, sets, rest)
+	runner.ExerciseID = tg.exerciseID
+	tg.program = nil
+	tg.steps = []*SetRunner{runner}
+}
+
+// useProgram plays every step of a program in order
+func (tg *TerminalGym) useProgram(program *Program) error {
+	steps := make([]*SetRunner, 0, len(program.Steps))
+	for i, step := range program.Steps {
+		info, err := tg.lookupExercise(step.Exercise)
+		if err != nil {
+			return fmt.Errorf("%s %w", tg.localizer.Tf("program_step", program.ID, i+1), err)
+		}
+		exercise := info.New(tg.localizer)
+		if step.Reps != 0 {
+			exercise.SetTarget(max(step.Reps, 0))
+		}
+		if step.Pattern != "" {
+			if err := applyPattern(tg.localizer, exercise, step.Pattern); err != nil {
+				return fmt.Errorf("%s %w", tg.localizer.Tf("program_step", program.ID, i+1), err)
+			}
+		}
+		runner := NewSetRunner(exercise, step.Sets, time.Duration(step.Rest))
+		runner.ExerciseID = info.ID
+		runner.TimeLimit = time.Duration(step.Duration)
+		steps = append(steps, runner)
+	}
+	
+	tg.program = program
+	tg.steps = steps
+	tg.currentExercise = steps[0].Exercise
+	return nil
+}
+
+// applyPattern sets the breathing pattern of a meditation exercise
+func applyPattern(localizer *Localizer, exercise Exercise, name string) error {
+	meditation, ok := exercise.(*MeditationExercise)
+	if !ok {
+		return errors.New(localizer.Tf("pattern_not_used", exercise.GetName()))
+	}
+	pattern, err := FindBreathingPattern(localizer, name)
+	if err != nil {
+		return err
 	}
+	meditation.SetPattern(pattern)
+	return nil
+}
+
+// startStep makes the given step the current one
+func (tg *TerminalGym) startStep(index int) {
+	tg.stepIndex = index
+	tg.workout = tg.steps[index]
+	tg.currentExercise = tg.workout.Exercise
+	tg.workout.Tempo = tg.tempo
+	tg.workout.Start()
+	tg.stepStart = tg.clock.Now()
+	tg.stepPaused = 0
+}
+
+// nextStep returns the step after the current one, if any
+func (tg *TerminalGym) nextStep() *SetRunner {
+	if tg.stepIndex+1 >= len(tg.steps) {
+		return nil
+	}
+	return tg.steps[tg.stepIndex+1]
 }
 
 func (tg *TerminalGym) clearScreen() {
-	fmt.Print("\033[H\033[2J")
+	fmt.Fprint(tg.out, clearAll)
 }
 
-func (tg *TerminalGym) selectExercise() {
-	tg.clearScreen()
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("                 " + tg.localizer.T("welcome_title"))
-	fmt.Println("                    " + tg.localizer.T("welcome_subtitle"))
-	fmt.Println(strings.Repeat("=", 60) + "\n")
+// header draws a centered title between two rules. The compact layout
+// keeps only the title line.
+func (tg *TerminalGym) header(w io.Writer, title, subtitle string, compact bool) {
+	if compact {
+		fmt.Fprintln(w, tg.layout.center(paint(tg.color, styleTitle, title)))
+		return
+	}
+	fmt.Fprintln(w, "\n" + tg.layout.rule("="))
+	fmt.Fprintln(w, tg.layout.center(paint(tg.color, styleTitle, title)))
+	fmt.Fprintln(w, tg.layout.center(subtitle))
+	fmt.Fprintln(w, tg.layout.rule("=") + "\n")
+}
+
+// tipsBox draws the tips between two rules. Tips are the first thing the
+// compact layout drops; the key help replaces them while it is toggled on.
+func (tg *TerminalGym) tipsBox(w io.Writer, tips []string, compact bool) {
+	header := tg.localizer.T("tips_header")
+	if tg.showHelp {
+		header = tg.localizer.T("keys_header")
+		tips = []string{
+			tg.localizer.T("key_pause"),
+			tg.localizer.T("key_tempo"),
+			tg.localizer.T("key_skip"),
+			tg.localizer.T("key_restart"),
+			tg.localizer.T("key_quit"),
+			tg.localizer.T("key_help"),
+		}
+		compact = false
+	}
+	if compact {
+		return
+	}
+	margin := tg.layout.margin()
+	fmt.Fprintln(w, "\n" + tg.layout.rule("-"))
+	fmt.Fprintln(w, margin + header)
+	for _, tip := range tips {
+		fmt.Fprintln(w, margin + tip)
+	}
+	fmt.Fprintln(w, tg.layout.rule("-"))
+}
+
+// status shows whether the session is paused and its tempo, when they
+// differ from normal
+func (tg *TerminalGym) status(w io.Writer) {
+	if tg.paused {
+		fmt.Fprintln(w, tg.layout.center(paint(tg.color, stylePaused, tg.localizer.T("paused"))))
+	}
+	if tg.tempo != 1 {
+		fmt.Fprintln(w, tg.layout.center(tg.localizer.Tf("tempo", tg.tempo)))
+	}
+}
+
+// selectExercise shows the exercise menu until the user picks one. It
+// returns false if the user left the menu instead.
+func (tg *TerminalGym) selectExercise() bool {
+	ids := exerciseIDs()
+	exercises := make([]Exercise, len(ids))
+	for i, id := range ids {
+		exercises[i], _ = tg.newExercise(id)
+	}
+	menu := NewMenu(ids, exercises)
+	menu.color = tg.color
 	
-	fmt.Println(tg.localizer.T("exercise_selection"))
-	fmt.Println(tg.localizer.T("exercise_buttock"))
-	fmt.Println(tg.localizer.T("exercise_meditation"))
-	fmt.Print("\n" + tg.localizer.T("enter_choice"))
+	// Being terminated or hung up leaves the menu like Esc does, so the
+	// terminal is restored on the way out
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(stop)
 	
-	reader := bufio.NewReader(os.Stdin)
+	tg.screen.Open()
+	tg.restoreInput = makeRaw(tg.in)
+	defer tg.leaveSession()
+	tg.resize()
+	
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+	defer signal.Stop(resized)
+	
+	keys := tg.keys()
 	for {
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			fmt.Printf("Error reading input: %v\n", err)
-			continue
-		}
+		frame := &Frame{}
+		tg.header(frame, tg.localizer.T("welcome_title"), tg.localizer.T("welcome_subtitle"), false)
+		menu.Render(frame, tg.layout, tg.localizer)
+		tg.screen.Draw(frame)
 		
-		choice := strings.TrimSpace(input)
-		choiceNum, err := strconv.Atoi(choice)
-		if err != nil || choiceNum < 1 || choiceNum > 2 {
-			fmt.Print(tg.localizer.T("invalid_choice") + "\n" + tg.localizer.T("enter_choice"))
-			continue
-		}
-		
-		switch choiceNum {
-		case 1:
-			tg.currentExercise = NewButtockExercise(tg.localizer)
-		case 2:
-			tg.currentExercise = NewMeditationExercise(tg.localizer)
+		select {
+		case <-stop:
+			return false
+		case <-resized:
+			tg.resize()
+		case key, ok := <-keys:
+			if !ok {
+				return false
+			}
+			chosen, cancelled := menu.HandleKey(key)
+			if cancelled {
+				return false
+			}
+			if chosen {
+				item, _ := menu.Selected()
+				tg.currentExercise = item.exercise
+				tg.exerciseID = item.id
+				return true
+			}
 		}
-		break
 	}
 }
 
+// keys starts reading key presses from the input, once, and returns the
+// channel they arrive on. The channel is closed when the input ends.
+func (tg *TerminalGym) keys() <-chan rune {
+	if tg.keyPresses == nil {
+		keys := make(chan rune)
+		go readKeys(bufio.NewReader(tg.in), keys)
+		tg.keyPresses = keys
+	}
+	return tg.keyPresses
+}
+
+// setOutput redirects everything the gym displays to out
+func (tg *TerminalGym) setOutput(out io.Writer) {
+	tg.out = out
+	tg.screen = NewScreen(out)
+	tg.layout = terminalLayout(out)
+}
+
+// resize rereads the terminal size and repaints the whole screen
+func (tg *TerminalGym) resize() {
+	tg.layout = terminalLayout(tg.out)
+	tg.screen.Resize(tg.layout.Width, tg.layout.Height)
+}
+
+// render draws the current state of the session to the screen
 func (tg *TerminalGym) render() {
-	tg.clearScreen()
-	
-	// Title
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("                    " + tg.localizer.T("title"))
-	fmt.Println("              " + tg.localizer.T("subtitle"))
-	fmt.Println(strings.Repeat("=", 60) + "\n")
+	tg.screen.Draw(tg.renderFrame())
+}
+
+// renderFrame renders the current state of the session into a frame,
+// switching to the compact layout when the full one doesn't fit
+func (tg *TerminalGym) renderFrame() *Frame {
+	frame := tg.drawFrame(false)
+	if len(frame.Lines()) > tg.layout.Height {
+		frame = tg.drawFrame(true)
+	}
+	return frame
+}
+
+func (tg *TerminalGym) drawFrame(compact bool) *Frame {
+	frame := &Frame{}
+	switch {
+	case tg.nextUpLeft > 0:
+		tg.renderNextUp(frame, compact)
+	case tg.workout.Resting():
+		tg.renderRest(frame, compact)
+	default:
+		tg.renderExercise(frame, compact)
+	}
+	return frame
+}
+
+// renderExercise draws the exercise animation with its instructions
+func (tg *TerminalGym) renderExercise(w io.Writer, compact bool) {
+	tg.header(w, tg.localizer.T("title"), tg.localizer.T("subtitle"), compact)
+	tg.status(w)
 	
 	// Instructions
-	instruction := tg.currentExercise.GetInstructions()
-	padding := (60 - len(instruction)) / 2
-	if padding < 0 {
-		padding = 0
-	}
-	fmt.Printf("%s%s\n\n", strings.Repeat(" ", padding), instruction)
+	fmt.Fprintln(w, tg.layout.center(tg.currentExercise.GetInstructions()))
 	
 	// Animation area
-	fmt.Println("\n" + strings.Repeat(" ", 25) + tg.localizer.T("watch_follow"))
-	fmt.Println()
+	if !compact {
+		fmt.Fprintln(w, "\n\n" + tg.layout.center(tg.localizer.T("watch_follow")))
+	}
+	fmt.Fprintln(w)
+	
+	// Render the current exercise, centered on the room it moves in
+	art := &Frame{}
+	tg.currentExercise.Render(art)
+	for _, line := range art.Lines() {
+		fmt.Fprintln(w, tg.layout.indent(line, tg.currentExercise.ArtWidth()))
+	}
 	
-	// Render the current exercise
-	tg.currentExercise.Render()
+	// Exercise counter
+	if !compact {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w, "\n" + tg.layout.center(tg.workout.GetCounter(tg.localizer)))
+	if next := tg.nextStep(); next != nil {
+		fmt.Fprintln(w, tg.layout.center(tg.localizer.Tf("next_up", next.Exercise.GetName())))
+	}
 	
-	// Exercise counter and tips
-	fmt.Printf("\n\n%s%s\n", strings.Repeat(" ", 25), tg.currentExercise.GetCounter())
+	tg.tipsBox(w, tg.currentExercise.GetTips(), compact)
+}
+
+// renderRest shows the countdown between two sets
+func (tg *TerminalGym) renderRest(w io.Writer, compact bool) {
+	tg.header(w, tg.localizer.T("title"), tg.localizer.T("subtitle"), compact)
+	tg.status(w)
 	
-	// Tips
-	fmt.Println("\n" + strings.Repeat("-", 60))
-	fmt.Println(tg.localizer.T("tips_header"))
-	for _, tip := range tg.currentExercise.GetTips() {
-		fmt.Println(tip)
+	remaining := int(tg.workout.RestRemaining().Round(time.Second) / time.Second)
+	fmt.Fprintln(w, "\n" + tg.layout.center(tg.localizer.T("rest_title")) + "\n")
+	for _, line := range tg.layout.block(
+		tg.localizer.Tf("rest_set_done", tg.workout.CompletedSets(), tg.workout.Sets),
+		tg.localizer.Tn("rest_countdown", Args{"seconds": remaining}),
+		"",
+		tg.localizer.Tf("rest_next", tg.workout.CurrentSet()+1, tg.currentExercise.GetName()),
+	) {
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
 	}
-	fmt.Println(strings.Repeat("-", 60))
+	
+	tg.tipsBox(w, []string{tg.localizer.T("tip_rest"), tg.localizer.T("tip_exit")}, compact)
+}
+
+// renderNextUp previews the upcoming program step
+func (tg *TerminalGym) renderNextUp(w io.Writer, compact bool) {
+	tg.header(w, tg.localizer.T("title"), tg.localizer.T("subtitle"), compact)
+	tg.status(w)
+	
+	exercise := tg.workout.Exercise
+	remaining := int((tg.nextUpLeft + time.Second - 1) / time.Second)
+	lines := []string{
+		tg.localizer.Tf("step_heading", tg.stepIndex+1, len(tg.steps), exercise.GetName()),
+		exercise.GetDescription(),
+		"",
+	}
+	if tg.workout.Sets > 1 {
+		lines = append(lines, tg.localizer.Tf("plan_sets", tg.workout.Sets, formatClock(tg.workout.Rest)))
+	}
+	if exercise.GetTarget() > 0 {
+		lines = append(lines, tg.localizer.Tf("plan_target", exercise.GetTarget()))
+	}
+	if tg.workout.TimeLimit > 0 {
+		lines = append(lines, tg.localizer.Tf("plan_time", formatClock(tg.workout.TimeLimit)))
+	}
+	lines = append(lines, "", tg.localizer.Tn("next_up_countdown", Args{"seconds": remaining}))
+	
+	fmt.Fprintln(w, "\n" + tg.layout.center(tg.localizer.T("next_up_title")) + "\n")
+	for _, line := range tg.layout.block(lines...) {
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+	
+	tg.tipsBox(w, []string{tg.localizer.T("tip_rest"), tg.localizer.T("tip_exit")}, compact)
+}
+
+// countdown gives the user a few seconds to get ready before the session
+func (tg *TerminalGym) countdown(seconds int) {
+	tg.clearScreen()
+	tg.header(tg.out, tg.localizer.T("welcome_title"), tg.localizer.T("welcome_subtitle"), false)
+	margin := tg.layout.margin()
+	fmt.Fprintln(tg.out, margin + tg.localizer.Tn("starting_countdown", Args{"seconds": seconds}))
+	fmt.Fprintln(tg.out, margin + tg.localizer.T("prepare_message"))
+	
+	for i := seconds; i > 0; i-- {
+		time.Sleep(time.Second)
+		fmt.Fprint(tg.out, "\r" + margin + tg.localizer.Tf("starting_in", i))
+	}
+	fmt.Fprintln(tg.out, "\n\n" + margin + tg.localizer.T("lets_begin"))
+	time.Sleep(time.Second)
 }
 
 func (tg *TerminalGym) run() {
 	// Set up signal handling for graceful exit
 	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	
+	// Redraw for the new size whenever the window is resized
+	resized := make(chan os.Signal, 1)
+	notifyResize(resized)
+	defer signal.Stop(resized)
+	
+	// Initialize the first exercise and its sets
+	tg.startStep(0)
 	
-	// Initialize the current exercise
-	tg.currentExercise.Reset()
+	// Record the current step even if the loop panics
+	defer func() {
+		tg.recordStep(tg.clock.Now())
+	}()
+	
+	// Draw on the alternate screen and read keys in raw mode; both are
+	// undone on every way out, including panics
+	tg.screen.Open()
+	tg.restoreInput = makeRaw(tg.in)
+	defer tg.leaveSession()
+	tg.resize()
+	
+	// Keys are only read from a terminal; scripts that pipe their own
+	// input into the gym keep it
+	var keys <-chan rune
+	if isTerminal(tg.in) {
+		keys = tg.keys()
+	}
 	
-	// Animation loop
+	// Animation loop; the ticker only paces rendering, timing comes from
+	// the clock so dropped or late frames don't stretch the exercise
 	ticker := time.NewTicker(time.Second / fps)
 	defer ticker.Stop()
+	last := tg.clock.Now()
 	
 	for {
 		select {
 		case <-c:
-			tg.clearScreen()
-			if tg.currentExercise.GetCategory() == "Meditation" {
-				fmt.Println("\n" + tg.localizer.T("meditation_complete"))
-			} else {
-				fmt.Println("\n" + tg.localizer.T("workout_complete"))
-			}
-			fmt.Println(tg.localizer.T("keep_work") + "\n")
+			tg.showSummary()
 			return
-		case <-ticker.C:
-			tg.currentExercise.Update()
+		case <-resized:
+			tg.resize()
+			tg.render()
+		case key, ok := <-keys:
+			if !ok {
+				// Input ended, e.g. the terminal closed; keep going without keys
+				keys = nil
+				continue
+			}
+			if tg.handleKey(key) {
+				tg.showSummary()
+				return
+			}
 			tg.render()
+		case <-ticker.C:
+			now := tg.clock.Now()
+			dt := now.Sub(last)
+			last = now
+			
+			if tg.tick(now, dt) {
+				tg.showSummary()
+				return
+			}
 		}
 	}
 }
 
+// tick advances the session to now, dt after the previous frame, and
+// reports whether the last step has ended
+func (tg *TerminalGym) tick(now time.Time, dt time.Duration) bool {
+	if tg.paused {
+		// Pauses during the preview come before the step starts
+		tg.pausedFor += dt
+		if tg.nextUpLeft <= 0 {
+			tg.stepPaused += dt
+		}
+		tg.render()
+		return false
+	}
+	
+	if tg.nextUpLeft > 0 {
+		// The step starts once its preview is over
+		tg.nextUpLeft -= dt
+		tg.stepStart = now
+		tg.render()
+		return false
+	}
+	
+	tg.workout.Update(dt)
+	tg.activeFor += dt
+	tg.tempoSeconds += tg.tempo * dt.Seconds()
+	if tg.workout.IsComplete() {
+		if tg.nextStep() == nil {
+			return true
+		}
+		tg.recordStep(now)
+		tg.startStep(tg.stepIndex + 1)
+		tg.nextUpLeft = nextUpDuration
+	}
+	tg.render()
+	return false
+}
+
+// handleKey applies a key pressed during the session and reports whether
+// the user asked to quit
+func (tg *TerminalGym) handleKey(key rune) bool {
+	switch key {
+	case keyQuit, keyCtrlC:
+		return true
+	case keyPause:
+		tg.paused = !tg.paused
+	case keyFaster, keyFaster2:
+		tg.setTempo(tg.tempo + tempoStep)
+	case keySlower:
+		tg.setTempo(tg.tempo - tempoStep)
+	case keySkip:
+		if tg.nextUpLeft > 0 {
+			tg.nextUpLeft = 0
+		} else {
+			tg.workout.SkipPhase()
+		}
+	case keyRestart:
+		tg.workout.RestartSet()
+	case keyHelp:
+		tg.showHelp = !tg.showHelp
+	}
+	return false
+}
+
+// setTempo changes the exercise speed within the allowed range
+func (tg *TerminalGym) setTempo(tempo float64) {
+	tg.tempo = min(max(tempo, minTempo), maxTempo)
+	tg.workout.Tempo = tg.tempo
+}
+
+// leaveSession restores the terminal mode and the normal screen buffer.
+// It is safe to call more than once.
+func (tg *TerminalGym) leaveSession() {
+	if tg.restoreInput != nil {
+		tg.restoreInput()
+		tg.restoreInput = nil
+	}
+	tg.screen.Close()
+}
+
+// recordStep keeps the current step for the summary and appends it to the
+// history, once
+func (tg *TerminalGym) recordStep(end time.Time) {
+	// A step still in its "next up" preview was never started
+	if tg.recordedSteps > tg.stepIndex || tg.workout == nil || tg.nextUpLeft > 0 {
+		return
+	}
+	tg.recordedSteps = tg.stepIndex + 1
+	
+	step := tg.workout
+	record := SessionRecord{
+		Exercise:  step.ExerciseID,
+		Start:     tg.stepStart,
+		End:       end,
+		Sets:      step.CompletedSets(),
+		Language:  tg.localizer.GetLanguage(),
+		Paused:    jsonDuration(tg.stepPaused),
+		Completed: step.IsComplete(),
+	}
+	if info, ok := FindExercise(step.ExerciseID); ok {
+		record.Category = info.Category
+	}
+	if tg.program != nil {
+		record.Program = tg.program.ID
+	}
+	if meditation, ok := step.Exercise.(*MeditationExercise); ok {
+		record.Cycles = step.TotalProgress()
+		record.Pattern = meditation.Pattern.ID
+		if record.Pattern == "custom" {
+			record.Pattern = meditation.Pattern.Name
+		}
+	} else {
+		record.Reps = step.TotalProgress()
+	}
+	
+	tg.records = append(tg.records, record)
+	if tg.historyPath == "" {
+		return
+	}
+	if err := AppendHistory(tg.historyPath, record); err != nil {
+		tg.historyErr = errors.Join(tg.historyErr, err)
+	}
+}
+
+// showSummary leaves the animation screen and prints the end-of-session
+// summary to the normal terminal so it stays in the scrollback
+func (tg *TerminalGym) showSummary() {
+	tg.leaveSession()
+	now := tg.clock.Now()
+	tg.recordStep(now)
+	
+	// The history, which now includes this session, gives the streak and
+	// the previous sessions to compare with
+	var history []SessionRecord
+	if tg.historyPath != "" {
+		history, _ = LoadHistory(tg.historyPath)
+	}
+	tg.writeSummary(tg.out, history, now)
+	
+	if tg.historyErr != nil {
+		fmt.Fprintln(tg.out, tg.localizer.T("history_write_warning"))
+		fmt.Fprintf(tg.out, "%v\n\n", tg.historyErr)
+	}
+}
+
+// withTarget appends the target to a counter, e.g. "Rep: 3/15"
+func withTarget(counter string, target int) string {
+	if target <= 0 {
+		return counter
+	}
+	return fmt.Sprintf("%s/%d", counter, target)
+}
+
 // Add sin function for smooth oscillations
 func sin(x float64) float64 {
 	// Simple sine approximation for smooth oscillations
@@ -839,49 +1496,25 @@ func abs(x float64) float64 {
 }
 
 func main() {
-	// Parse command line arguments
-	lang := flag.String("lang", "en", "Language (en/zh)")
-	help := flag.Bool("help", false, "Show help")
-	flag.Parse()
+	args := os.Args[1:]
 	
-	// Initialize localizer
-	localizer, err := NewLocalizer(*lang)
+	// Settings from the config file and the environment; flags are
+	// applied on top by each command
+	var configErr error
+	config, configErr = LoadConfig()
+	
+	// Initialize localizer. Errors come from user translation files; the
+	// shipped translations are used without them.
+	localizer, err := NewLocalizer(langFromArgs(args))
 	if err != nil {
-		fmt.Printf("Error initializing localizer: %v\n", err)
-		fmt.Println("Falling back to English...")
-		localizer, _ = NewLocalizer("en")
+		warn(localizer.T("locale_warning"), err)
 	}
-	
-	if *help {
-		fmt.Println(localizer.T("language_help"))
-		return
+	if configErr != nil {
+		warn(localizer.T("config_warning"), configErr)
 	}
 	
-	// Hide cursor for better animation experience
-	fmt.Print("\033[?25l")
-	defer fmt.Print("\033[?25h") // Show cursor on exit
-	
-	gym := NewTerminalGym(localizer)
-	
-	// Exercise selection
-	gym.selectExercise()
-	
-	// Preparation phase
-	fmt.Print("\033[H\033[2J")
-	fmt.Println("\n" + strings.Repeat("=", 60))
-	fmt.Println("                 " + localizer.T("welcome_title"))
-	fmt.Println("                    " + localizer.T("welcome_subtitle"))
-	fmt.Println(strings.Repeat("=", 60))
-	fmt.Println("\n" + localizer.T("starting_countdown"))
-	fmt.Println(localizer.T("prepare_message"))
-	
-	// Countdown
-	for i := 3; i > 0; i-- {
-		time.Sleep(time.Second)
-		fmt.Printf("\r" + localizer.Tf("starting_in", i))
+	if err := runCLI(localizer, args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
-	fmt.Println("\n\n" + localizer.T("lets_begin"))
-	time.Sleep(time.Second)
-	
-	gym.run()
-}
\ No newline at end of file
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// menuItem is one exercise offered by the menu
+type menuItem struct {
+	number   int // position in the unfiltered list, shown next to the name
+	id       string
+	exercise Exercise
+}
+
+// Menu lets the user pick an exercise with the arrow keys or j/k. Items
+// are grouped by category, the highlighted item's description is shown
+// below the list, and typing narrows the list down.
+type Menu struct {
+	items     []menuItem
+	filter    string
+	filtering bool
+	cursor    int
+
+	// color draws the highlighted item in color
+	color bool
+}
+
+// NewMenu groups exercises by category, keeping the order in which the
+// categories first appear
+func NewMenu(ids []string, exercises []Exercise) *Menu {
+	var categories []string
+	byCategory := map[string][]menuItem{}
+	for i, exercise := range exercises {
+		category := exercise.GetCategory()
+		if _, ok := byCategory[category]; !ok {
+			categories = append(categories, category)
+		}
+		byCategory[category] = append(byCategory[category], menuItem{id: ids[i], exercise: exercise})
+	}
+
+	m := &Menu{}
+	for _, category := range categories {
+		for _, item := range byCategory[category] {
+			item.number = len(m.items) + 1
+			m.items = append(m.items, item)
+		}
+	}
+	return m
+}
+
+// visible returns the items that match the filter, in display order
+func (m *Menu) visible() []menuItem {
+	if m.filter == "" {
+		return m.items
+	}
+	filter := strings.ToLower(m.filter)
+	var matches []menuItem
+	for _, item := range m.items {
+		text := strings.ToLower(item.exercise.GetName() + " " + item.exercise.GetCategory() + " " + item.id)
+		if strings.Contains(text, filter) {
+			matches = append(matches, item)
+		}
+	}
+	return matches
+}
+
+// Selected returns the highlighted item, if any item is visible
+func (m *Menu) Selected() (menuItem, bool) {
+	items := m.visible()
+	if len(items) == 0 {
+		return menuItem{}, false
+	}
+	return items[min(m.cursor, len(items)-1)], true
+}
+
+// HandleKey applies a key press. It reports whether the user chose the
+// highlighted item or asked to leave the menu.
+func (m *Menu) HandleKey(key rune) (chosen, cancelled bool) {
+	switch {
+	case key == keyEnter || key == keyNewline:
+		_, ok := m.Selected()
+		return ok, false
+	case key == keyCtrlC:
+		return false, true
+	case key == keyEscape:
+		if m.filter == "" && !m.filtering {
+			return false, true
+		}
+		m.setFilter("")
+		m.filtering = false
+	case key == keyUp:
+		m.move(-1)
+	case key == keyDown:
+		m.move(1)
+	case key == keyBackspace || key == keyCtrlH:
+		if m.filter != "" {
+			runes := []rune(m.filter)
+			m.setFilter(string(runes[:len(runes)-1]))
+		}
+	case !m.filtering && key == keyUpK:
+		m.move(-1)
+	case !m.filtering && key == keyDownJ:
+		m.move(1)
+	case !m.filtering && key == keyFilter:
+		m.filtering = true
+	case !m.filtering && key >= '1' && key <= '9':
+		// Numbers jump straight to an item, which also keeps piped input
+		// such as "2\n" working
+		if n := int(key - '0'); n <= len(m.items) {
+			m.cursor = n - 1
+		}
+	case unicode.IsPrint(key):
+		m.filtering = true
+		m.setFilter(m.filter + string(key))
+	}
+	return false, false
+}
+
+// move shifts the highlight, wrapping around at either end
+func (m *Menu) move(delta int) {
+	n := len(m.visible())
+	if n == 0 {
+		return
+	}
+	m.cursor = (min(m.cursor, n-1) + delta + n) % n
+}
+
+func (m *Menu) setFilter(filter string) {
+	m.filter = filter
+	m.cursor = 0
+}
+
+// Render draws the menu into w
+func (m *Menu) Render(w io.Writer, layout Layout, localizer *Localizer) {
+	margin := layout.margin()
+	fmt.Fprintln(w, margin+localizer.T("exercise_selection"))
+	fmt.Fprintln(w, margin+localizer.T("menu_help"))
+	if m.filtering {
+		fmt.Fprintln(w, margin+localizer.Tf("menu_filter", m.filter))
+	}
+	fmt.Fprintln(w)
+
+	items := m.visible()
+	if len(items) == 0 {
+		fmt.Fprintln(w, margin+localizer.T("menu_no_match"))
+	}
+	selected, _ := m.Selected()
+	category := ""
+	for i, item := range items {
+		if i == 0 || item.exercise.GetCategory() != category {
+			category = item.exercise.GetCategory()
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, margin+"▸ "+category)
+		}
+		line := fmt.Sprintf("  %d. %s", item.number, item.exercise.GetName())
+		if item.number == selected.number {
+			line = paint(m.color, styleSelected, fmt.Sprintf("❯ %d. %s", item.number, item.exercise.GetName()))
+		}
+		fmt.Fprintf(w, "%s  %s\n", margin, line)
+	}
+
+	if len(items) > 0 {
+		fmt.Fprintln(w, "\n"+layout.rule("-"))
+		fmt.Fprintln(w, margin+selected.exercise.GetDescription())
+		fmt.Fprintln(w, layout.rule("-"))
+	}
+}
@@ -0,0 +1,363 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Args are the named arguments of a message
+type Args map[string]any
+
+// isMessageFormat reports whether a template uses the ICU message syntax
+// with {arguments} rather than fmt verbs
+func isMessageFormat(template string) bool {
+	return strings.Contains(template, "{")
+}
+
+// formatMessage fills in an ICU-style message:
+//
+//	{name}                                       the argument as text
+//	{count, plural, one {# rep} other {# reps}}  plural forms; # is the number
+//	{count, plural, =0 {none} other {#}}         exact matches come first
+//	{kind, select, rest {…} other {…}}           a form per value
+//
+// Plural categories follow the CLDR rules of lang. As in ICU, a single
+// quote escapes the braces and # that follow it, and two quotes are one.
+func formatMessage(template, lang string, args Args) (string, error) {
+	p := &messageParser{text: template, lang: lang, args: args}
+	return p.parse()
+}
+
+// messageArguments returns the names of the arguments a message uses, in
+// every form, sorted. It fails if the message is malformed.
+func messageArguments(template string) ([]string, error) {
+	p := &messageParser{text: template, lang: referenceLocale, names: map[string]bool{}}
+	if _, err := p.parse(); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(p.names))
+	for name := range p.names {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// messageParser formats a message while parsing it
+type messageParser struct {
+	text string
+	pos  int
+	lang string
+	args Args
+
+	// names, if set, collects the argument names instead of requiring
+	// their values
+	names map[string]bool
+}
+
+// parse formats the whole template
+func (p *messageParser) parse() (string, error) {
+	out, err := p.message(false, nil)
+	if err != nil {
+		return "", err
+	}
+	if p.pos < len(p.text) {
+		return "", fmt.Errorf("unexpected } at offset %d", p.pos)
+	}
+	return out, nil
+}
+
+// message formats text up to the end of a sub-message, or of the whole
+// template. number replaces # inside plural forms.
+func (p *messageParser) message(nested bool, number any) (string, error) {
+	var b strings.Builder
+	for p.pos < len(p.text) {
+		c := p.text[p.pos]
+		switch {
+		case c == '}':
+			// The end of a form; the caller consumes the brace
+			return b.String(), nil
+		case c == '{':
+			p.pos++
+			arg, err := p.argument()
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(arg)
+		case c == '#' && number != nil:
+			p.pos++
+			b.WriteString(formatValue(number))
+		case c == '\'':
+			p.pos++
+			b.WriteString(p.quoted(number != nil))
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	if nested {
+		return "", fmt.Errorf("missing } at the end of the message")
+	}
+	return b.String(), nil
+}
+
+// quoted handles the text after a single quote: a doubled quote is one
+// quote, and a quote before a brace, or a # in a plural form, starts
+// literal text up to the next quote. Other quotes are just quotes, as in
+// "Let's".
+func (p *messageParser) quoted(inPlural bool) string {
+	if p.pos < len(p.text) && p.text[p.pos] == '\'' {
+		p.pos++
+		return "'"
+	}
+	if p.pos >= len(p.text) || !strings.ContainsRune("{}", rune(p.text[p.pos])) && !(inPlural && p.text[p.pos] == '#') {
+		return "'"
+	}
+	end := strings.IndexByte(p.text[p.pos:], '\'')
+	if end < 0 {
+		literal := p.text[p.pos:]
+		p.pos = len(p.text)
+		return literal
+	}
+	literal := p.text[p.pos : p.pos+end]
+	p.pos += end + 1
+	return literal
+}
+
+// argument formats an {argument} whose opening brace has been read
+func (p *messageParser) argument() (string, error) {
+	name := p.token()
+	if name == "" {
+		return "", fmt.Errorf("missing argument name at offset %d", p.pos)
+	}
+	value, ok := p.args[name]
+	if p.names != nil {
+		p.names[name] = true
+		value, ok = 0, true
+	}
+	if !ok {
+		return "", fmt.Errorf("missing argument %q", name)
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.text) && p.text[p.pos] == '}' {
+		p.pos++
+		return formatValue(value), nil
+	}
+	if !p.consume(',') {
+		return "", fmt.Errorf("expected , or } after %q", name)
+	}
+	kind := p.token()
+	if !p.consume(',') {
+		return "", fmt.Errorf("expected , after %s in {%s}", kind, name)
+	}
+
+	var selector func(key string) bool
+	var number any
+	switch kind {
+	case "plural":
+		n, ok := toFloat(value)
+		if !ok {
+			return "", fmt.Errorf("argument %q of a plural must be a number", name)
+		}
+		category := pluralCategory(p.lang, n)
+		exact := "=" + strconv.FormatFloat(n, 'f', -1, 64)
+		number = value
+		// An exact match wins over the category
+		selector = func(key string) bool { return key == exact }
+		if p.hasForm(exact) {
+			break
+		}
+		selector = func(key string) bool { return key == category }
+		if !p.hasForm(category) {
+			selector = func(key string) bool { return key == "other" }
+		}
+	case "select":
+		text := fmt.Sprint(value)
+		selector = func(key string) bool { return key == text }
+		if !p.hasForm(text) {
+			selector = func(key string) bool { return key == "other" }
+		}
+	default:
+		return "", fmt.Errorf("unknown argument type %q in {%s}", kind, name)
+	}
+
+	// Format the chosen form and skip the others
+	result, found := "", false
+	for {
+		p.skipSpace()
+		if p.consume('}') {
+			break
+		}
+		key := p.token()
+		if key == "" || !p.consume('{') {
+			return "", fmt.Errorf("expected a form like other {…} in {%s}", name)
+		}
+		form, err := p.message(true, number)
+		if err != nil {
+			return "", err
+		}
+		p.pos++ // the closing brace of the form
+		if !found && selector(key) {
+			result, found = form, true
+		}
+	}
+	if !found {
+		return "", fmt.Errorf("{%s} has no other form", name)
+	}
+	return result, nil
+}
+
+// hasForm looks ahead for a form with the given key in the current
+// plural or select argument, without moving on
+func (p *messageParser) hasForm(key string) bool {
+	saved := p.pos
+	defer func() { p.pos = saved }()
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.text) || p.text[p.pos] == '}' {
+			return false
+		}
+		k := p.token()
+		if k == "" || !p.consume('{') {
+			return false
+		}
+		if k == key {
+			return true
+		}
+		if !p.skipForm() {
+			return false
+		}
+	}
+}
+
+// skipForm moves past a form whose opening brace has been read
+func (p *messageParser) skipForm() bool {
+	depth := 1
+	for p.pos < len(p.text) {
+		switch p.text[p.pos] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return true
+			}
+		}
+		p.pos++
+	}
+	return false
+}
+
+// token reads a name, keyword or form key
+func (p *messageParser) token() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.text) && !strings.ContainsRune("{},", rune(p.text[p.pos])) && p.text[p.pos] != ' ' {
+		p.pos++
+	}
+	return p.text[start:p.pos]
+}
+
+func (p *messageParser) consume(c byte) bool {
+	p.skipSpace()
+	if p.pos < len(p.text) && p.text[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *messageParser) skipSpace() {
+	for p.pos < len(p.text) && strings.ContainsRune(" \t\n", rune(p.text[p.pos])) {
+		p.pos++
+	}
+}
+
+// formatValue writes an argument as text
+func formatValue(value any) string {
+	if f, ok := value.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// pluralCategory returns the CLDR plural category of n in lang: zero,
+// one, two, few, many or other. Only the integer rules are implemented;
+// fractions are "other".
+func pluralCategory(lang string, n float64) string {
+	if n != float64(int64(n)) {
+		return "other"
+	}
+	i := int64(n)
+	if i < 0 {
+		i = -i
+	}
+	mod10, mod100 := i%10, i%100
+
+	base, _, _ := strings.Cut(lang, "-")
+	switch base {
+	case "zh", "ja", "ko", "th", "vi", "id", "ms":
+		return "other"
+	case "fr", "pt", "hi":
+		if i <= 1 {
+			return "one"
+		}
+	case "ru", "uk", "be":
+		switch {
+		case mod10 == 1 && mod100 != 11:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		}
+		return "many"
+	case "pl":
+		switch {
+		case i == 1:
+			return "one"
+		case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+			return "few"
+		}
+		return "many"
+	case "cs", "sk":
+		switch {
+		case i == 1:
+			return "one"
+		case i >= 2 && i <= 4:
+			return "few"
+		}
+	case "ar":
+		switch {
+		case i == 0:
+			return "zero"
+		case i == 1:
+			return "one"
+		case i == 2:
+			return "two"
+		case mod100 >= 3 && mod100 <= 10:
+			return "few"
+		case mod100 >= 11:
+			return "many"
+		}
+	default:
+		if i == 1 {
+			return "one"
+		}
+	}
+	return "other"
+}
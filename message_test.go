@@ -0,0 +1,92 @@
+package main
+
+import "testing"
+
+func TestFormatMessage(t *testing.T) {
+	tests := []struct {
+		template string
+		lang     string
+		args     Args
+		want     string
+	}{
+		{"Hello {name}", "en", Args{"name": "Ada"}, "Hello Ada"},
+		{"{count, plural, one {# rep} other {# reps}}", "en", Args{"count": 1}, "1 rep"},
+		{"{count, plural, one {# rep} other {# reps}}", "en", Args{"count": 0}, "0 reps"},
+		{"{count, plural, =0 {no reps} one {# rep} other {# reps}}", "en", Args{"count": 0}, "no reps"},
+		{"{count, plural, one {# rep} other {# reps}}", "zh", Args{"count": 1}, "1 reps"},
+		{"{count, plural, one {# fois} other {# fois}}", "fr", Args{"count": 0}, "0 fois"},
+		{"{n, plural, one {# день} few {# дня} many {# дней} other {# дня}}", "ru", Args{"n": 22}, "22 дня"},
+		{"{n, plural, one {# день} few {# дня} many {# дней} other {# дня}}", "ru", Args{"n": 11}, "11 дней"},
+		{"{n, plural, one {# day} other {# days}}", "en-GB", Args{"n": 1}, "1 day"},
+		{"{kind, select, rest {Rest} other {Go}}!", "en", Args{"kind": "rest"}, "Rest!"},
+		{"{a} before {b}", "en", Args{"a": 1, "b": 2.5}, "1 before 2.5"},
+		{"Let's go, '{'braces'}' and ''quotes''", "en", nil, "Let's go, {braces} and 'quotes'"},
+		{"{n, plural, other {'#' #}}", "en", Args{"n": 3}, "# 3"},
+	}
+
+	for _, tt := range tests {
+		got, err := formatMessage(tt.template, tt.lang, tt.args)
+		if err != nil {
+			t.Errorf("formatMessage(%q) error: %v", tt.template, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("formatMessage(%q, %s, %v) = %q, want %q", tt.template, tt.lang, tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestFormatMessageErrors(t *testing.T) {
+	for _, template := range []string{
+		"{count",
+		"{missing}",
+		"{count, plural, one {# rep}}",
+		"{count, ordinal, other {#}}",
+		"{count, plural, other {# reps}",
+		"extra }",
+	} {
+		if got, err := formatMessage(template, "en", Args{"count": 2}); err == nil {
+			t.Errorf("formatMessage(%q) = %q, want an error", template, got)
+		}
+	}
+}
+
+func TestTfKeepsPrintfTemplates(t *testing.T) {
+	localizer := newTestLocalizer(t)
+	localizer.translations["en"]["test_printf"] = "Rep: %d"
+	localizer.translations["en"]["test_positional"] = "{1} of {0, plural, one {# set} other {# sets}}"
+
+	if got, want := localizer.Tf("test_printf", 3), "Rep: 3"; got != want {
+		t.Errorf("Tf(printf) = %q, want %q", got, want)
+	}
+	if got, want := localizer.Tf("test_positional", 1, "Set 1"), "Set 1 of 1 set"; got != want {
+		t.Errorf("Tf(positional) = %q, want %q", got, want)
+	}
+}
+
+// A key missing from a partial locale falls back to English, and is
+// formatted with the English plural rules in and out of debug mode
+func TestPluralsFollowTheServingLocale(t *testing.T) {
+	for _, debug := range []bool{false, true} {
+		localizer := &Localizer{
+			chain: []string{"ru", "en"},
+			translations: map[string]map[string]string{
+				"ru": {"test_days": "{count, plural, one {# день} few {# дня} many {# дней} other {# дня}}"},
+				"en": {"test_reps": "{count, plural, one {# rep} other {# reps}}"},
+			},
+			language: "ru",
+			debug:    debug,
+		}
+
+		want, wantDays := "21 reps", "21 день"
+		if debug {
+			want, wantDays = "[en]"+want, "[ru]"+wantDays
+		}
+		if got := localizer.Tn("test_reps", Args{"count": 21}); got != want {
+			t.Errorf("debug %v: Tn(fallback) = %q, want %q", debug, got, want)
+		}
+		if got := localizer.Tn("test_days", Args{"count": 21}); got != wantDays {
+			t.Errorf("debug %v: Tn(ru) = %q, want %q", debug, got, wantDays)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// nextUpDuration is how long the "next up" preview shows between steps
+const nextUpDuration = 5 * time.Second
+
+// Program is a sequence of exercises played back to back, e.g. a breathing
+// warm-up followed by strength sets and a breathing cool-down
+type Program struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description"`
+	Steps       []ProgramStep `json:"steps"`
+
+	// Path is the file the program was loaded from, empty for built-ins
+	Path string `json:"-"`
+}
+
+// ProgramStep is one exercise within a program
+type ProgramStep struct {
+	// Exercise is the ID of a built-in or defined exercise
+	Exercise string `json:"exercise"`
+	Sets     int    `json:"sets"`
+	// Reps per set; 0 uses the exercise default, -1 removes the limit
+	Reps int          `json:"reps"`
+	Rest jsonDuration `json:"rest"`
+	// Duration ends the step after this long even if reps remain
+	Duration jsonDuration `json:"duration"`
+	// Pattern is the breathing pattern for meditation steps
+	Pattern string `json:"pattern"`
+}
+
+// jsonDuration reads durations such as "45s" or "2m" from JSON
+type jsonDuration time.Duration
+
+func (d *jsonDuration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"45s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = jsonDuration(parsed)
+	return nil
+}
+
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// builtinPrograms are always available, even without program files
+var builtinPrograms = []*Program{
+	{
+		ID:          "glute_session",
+		Name:        "program_glute_session_name",
+		Description: "program_glute_session_description",
+		Steps: []ProgramStep{
+			{Exercise: "meditation", Reps: -1, Duration: jsonDuration(2 * time.Minute)},
+			{Exercise: "buttock", Sets: 3, Reps: 15, Rest: jsonDuration(45 * time.Second)},
+			{Exercise: "meditation", Reps: 4, Pattern: "478"},
+		},
+	},
+}
+
+// Validate checks that the program has steps that can be played
+func (p *Program) Validate() error {
+	if p.ID == "" {
+		return errors.New("missing id")
+	}
+	if len(p.Steps) == 0 {
+		return errors.New("at least one step is required")
+	}
+	for i, step := range p.Steps {
+		if step.Exercise == "" {
+			return fmt.Errorf("step %d: missing exercise", i+1)
+		}
+		if step.Sets < 0 {
+			return fmt.Errorf("step %d: sets must not be negative", i+1)
+		}
+		if step.Rest < 0 || step.Duration < 0 {
+			return fmt.Errorf("step %d: durations must not be negative", i+1)
+		}
+	}
+	return nil
+}
+
+// embeddedPrograms holds the shipped program files
+//
+//go:embed programs/*.json
+var embeddedPrograms embed.FS
+
+// LoadProgram reads and validates a single program file
+func LoadProgram(filename string) (*Program, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read program %s: %w", filename, err)
+	}
+	return parseProgram(filename, data)
+}
+
+// loadEmbeddedProgram reads and validates a shipped program file
+func loadEmbeddedProgram(name string) (*Program, error) {
+	data, err := embeddedPrograms.ReadFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read program %s: %w", name, err)
+	}
+	return parseProgram(name, data)
+}
+
+func parseProgram(filename string, data []byte) (*Program, error) {
+	program := &Program{}
+	if err := decodeDataFile(filename, data, program); err != nil {
+		return nil, fmt.Errorf("failed to parse program %s: %w", filename, err)
+	}
+	program.ID = normalizeID(program.ID)
+	if err := program.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid program %s: %w", filename, err)
+	}
+	program.Path = filename
+
+	return program, nil
+}
+
+// LoadPrograms returns the built-in programs followed by every JSON and YAML
+// program in the given directories and then the shipped program files.
+// Files never replace a built-in program; see loadFirstByID.
+func LoadPrograms(dirs ...string) ([]*Program, error) {
+	return loadFirstByID(builtinPrograms, func(p *Program) string { return p.ID },
+		dirs, LoadProgram, embeddedPrograms, programsDir, loadEmbeddedProgram)
+}
+
+// FindProgram looks a program up by ID, or loads it when name is a path
+func FindProgram(localizer *Localizer, name string, programs []*Program) (*Program, error) {
+	for _, p := range programs {
+		if p.ID == normalizeID(name) {
+			return p, nil
+		}
+	}
+	if isDataFile(name) {
+		return LoadProgram(name)
+	}
+	return nil, errors.New(localizer.Tf("program_unknown", name))
+}
+
+// programDirs returns the directories searched for program files
+func programDirs() []string {
+	dirs := []string{programsDir}
+	if configDir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(configDir, "terminal-gym", "programs"))
+	}
+	return dirs
+}
+
+// formatClock formats a duration as m:ss
+func formatClock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	seconds := int(d.Round(time.Second) / time.Second)
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
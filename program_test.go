@@ -0,0 +1,58 @@
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+const yamlProgram = `# Calf raises between two breathing steps
+id: Evening
+name: Evening
+steps:
+  - { exercise: meditation, reps: 4, pattern: "478" }
+  - { exercise: calf_raises, sets: 2, reps: 10, rest: 30s }
+`
+
+func TestLoadProgramsFormats(t *testing.T) {
+	dir := t.TempDir()
+	writeDefinition(t, dir, "evening.yaml", yamlProgram)
+	writeDefinition(t, dir, "quick.json", `{"id": "Quick_Break", "name": "Mine", "steps": [{"exercise": "buttock"}]}`)
+	writeDefinition(t, dir, "glute.yml", "id: glute_session\nname: Mine\nsteps: [{exercise: buttock}]\n")
+
+	programs, err := LoadPrograms(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, p := range programs {
+		ids = append(ids, p.ID)
+	}
+	// Files on disk replace the shipped quick_break but not the built-in
+	// glute_session
+	if want := []string{"glute_session", "evening", "quick_break"}; !slices.Equal(ids, want) {
+		t.Errorf("ids = %q, want %q", ids, want)
+	}
+	if programs[0].Path != "" || programs[2].Path != filepath.Join(dir, "quick.json") {
+		t.Errorf("glute_session from %q and quick_break from %q, want the built-in and the file on disk",
+			programs[0].Path, programs[2].Path)
+	}
+
+	evening := programs[1]
+	if len(evening.Steps) != 2 || evening.Steps[0].Pattern != "478" ||
+		time.Duration(evening.Steps[1].Rest) != 30*time.Second {
+		t.Errorf("steps = %+v", evening.Steps)
+	}
+
+	localizer := newTestLocalizer(t)
+	for _, name := range []string{"Evening", " EVENING ", "evening"} {
+		if p, err := FindProgram(localizer, name, programs); err != nil || p != evening {
+			t.Errorf("FindProgram(%q) = %v, %v; want the evening program", name, p, err)
+		}
+	}
+	p, err := FindProgram(localizer, filepath.Join(dir, "evening.yaml"), nil)
+	if err != nil || p.ID != "evening" {
+		t.Errorf("FindProgram by path = %v, %v; want the evening program", p, err)
+	}
+}
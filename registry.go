@@ -0,0 +1,145 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Category IDs of the built-in exercises. The name shown for a category
+// comes from the category_<id> locale key.
+const (
+	categoryStrength   = "strength"
+	categoryMeditation = "meditation"
+)
+
+// ExerciseInfo describes an exercise the gym can play. Built-in exercises
+// register themselves from init; exercise definition files are registered
+// once they have been loaded.
+type ExerciseInfo struct {
+	ID       string
+	Category string // a category ID such as "strength"
+	New      func(localizer *Localizer) Exercise
+
+	// Source is "built-in" or the file the exercise was defined in
+	Source string
+}
+
+const builtinSource = "built-in"
+
+// exerciseRegistry holds every registered exercise in registration order
+var exerciseRegistry []*ExerciseInfo
+
+// RegisterExercise adds an exercise to the registry. IDs must be unique.
+func RegisterExercise(info *ExerciseInfo) error {
+	if info.ID == "" {
+		return fmt.Errorf("exercise registered without an id")
+	}
+	if info.New == nil {
+		return fmt.Errorf("exercise %s: missing constructor", info.ID)
+	}
+	if existing, ok := FindExercise(info.ID); ok {
+		return fmt.Errorf("exercise %s from %s: id already registered by %s", info.ID, info.Source, existing.Source)
+	}
+	exerciseRegistry = append(exerciseRegistry, info)
+	return nil
+}
+
+// RegisterDefinitions registers exercises loaded from definition files.
+// Definitions whose ID is taken are skipped and reported.
+func RegisterDefinitions(definitions []*ExerciseDefinition) error {
+	var errs []error
+	for _, def := range definitions {
+		err := RegisterExercise(&ExerciseInfo{
+			ID:       def.ID,
+			Category: categoryID(def.Category),
+			New: func(localizer *Localizer) Exercise {
+				return NewDefinedExercise(def, localizer)
+			},
+			Source: def.Path,
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// categoryID turns the category of a definition file into an ID, so
+// "Strength" files from before categories had IDs keep working
+func categoryID(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+// categoryName returns the localized name of a category. Categories
+// without a category_<id> translation are shown as they are written.
+func categoryName(localizer *Localizer, category string) string {
+	key := "category_" + categoryID(category)
+	if _, _, ok := localizer.lookup(key); ok {
+		return localizer.T(key)
+	}
+	return category
+}
+
+// FindExercise looks up a registered exercise by ID
+func FindExercise(id string) (*ExerciseInfo, bool) {
+	id = normalizeID(id)
+	for _, info := range exerciseRegistry {
+		if info.ID == id {
+			return info, true
+		}
+	}
+	return nil, false
+}
+
+// Exercises returns every registered exercise, built-ins first
+func Exercises() []*ExerciseInfo {
+	return exerciseRegistry
+}
+
+// exerciseIDs lists the IDs of every registered exercise
+func exerciseIDs() []string {
+	ids := make([]string, len(Exercises()))
+	for i, info := range Exercises() {
+		ids[i] = info.ID
+	}
+	return ids
+}
+
+// listExercises writes the registered exercises grouped by category,
+// either as plain text or as a Markdown table for the documentation
+func listExercises(w io.Writer, localizer *Localizer, markdown bool) {
+	exercises := make([]Exercise, len(Exercises()))
+	for i, info := range Exercises() {
+		exercises[i] = info.New(localizer)
+	}
+	menu := NewMenu(exerciseIDs(), exercises)
+
+	if markdown {
+		fmt.Fprintln(w, "| ID | Name | Category | Description |")
+		fmt.Fprintln(w, "|----|------|----------|-------------|")
+		for _, item := range menu.items {
+			e := item.exercise
+			fmt.Fprintf(w, "| `%s` | %s | %s | %s |\n", item.id, e.GetName(), e.GetCategory(), e.GetDescription())
+		}
+		return
+	}
+
+	idWidth := 0
+	for _, item := range menu.items {
+		idWidth = max(idWidth, len(item.id))
+	}
+	category := ""
+	for i, item := range menu.items {
+		e := item.exercise
+		if i == 0 || e.GetCategory() != category {
+			category = e.GetCategory()
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, category)
+		}
+		fmt.Fprintf(w, "  %-*s  %s - %s\n", idWidth, item.id, e.GetName(), e.GetDescription())
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// assertGolden compares got with testdata/<name>.golden
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run with -update to create it): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("frame does not match %s\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+}
+
+func newTestLocalizer(t *testing.T) *Localizer {
+	t.Helper()
+	localizer, err := NewLocalizer("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return localizer
+}
+
+// step advances an exercise by n fixed physics steps
+func step(exercise interface{ Update(time.Duration) }, n int) {
+	for i := 0; i < n; i++ {
+		exercise.Update(physicsStep)
+	}
+}
+
+func TestExerciseFramesGolden(t *testing.T) {
+	localizer := newTestLocalizer(t)
+	calf, err := LoadExerciseDefinition(filepath.Join("exercises", "calf_raises.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	box, err := FindBreathingPattern(localizer, "box")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		exercise func() Exercise
+		steps    int
+	}{
+		{"buttock_start", func() Exercise { return NewButtockExercise(localizer) }, 1},
+		{"buttock_1s", func() Exercise { return NewButtockExercise(localizer) }, fps},
+		{"buttock_3s", func() Exercise { return NewButtockExercise(localizer) }, 3 * fps},
+		{"meditation_start", func() Exercise { return NewMeditationExercise(localizer) }, 1},
+		{"meditation_hold", func() Exercise { return NewMeditationExercise(localizer) }, 5 * fps},
+		{"meditation_box_pause", func() Exercise {
+			me := NewMeditationExercise(localizer)
+			me.SetPattern(box)
+			return me
+		}, 13 * fps},
+		{"calf_raises_top", func() Exercise { return NewDefinedExercise(calf, localizer) }, 2 * fps},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exercise := tt.exercise()
+			exercise.Reset()
+			step(exercise, tt.steps)
+
+			var buf bytes.Buffer
+			exercise.Render(&buf)
+			buf.WriteString("\n" + exercise.GetInstructions() + "\n" + exercise.GetCounter() + "\n")
+			assertGolden(t, tt.name, buf.String())
+		})
+	}
+}
+
+func TestSessionFramesGolden(t *testing.T) {
+	localizer := newTestLocalizer(t)
+
+	gym := NewTerminalGym(localizer)
+	gym.setOutput(&bytes.Buffer{})
+	gym.layout = Layout{Width: 80, Height: 50}
+	gym.currentExercise = NewButtockExercise(localizer)
+	gym.currentExercise.SetTarget(1)
+	gym.useExercise(2, 10*time.Second)
+	gym.startStep(0)
+
+	step(gym.workout, 20)
+	assertGolden(t, "session_exercise", gym.renderFrame().buf.String())
+
+	// A short window drops the tips and spacing
+	gym.layout = Layout{Width: 50, Height: 24}
+	assertGolden(t, "session_exercise_compact", gym.renderFrame().buf.String())
+	gym.layout = Layout{Width: 80, Height: 50}
+
+	// Finish the first set to reach the rest screen
+	for !gym.workout.Resting() {
+		gym.workout.Update(physicsStep)
+	}
+	step(gym.workout, 3*fps)
+	assertGolden(t, "session_rest", gym.renderFrame().buf.String())
+}
+
+// fixedClock is a clock tests move by hand
+type fixedClock struct{ now time.Time }
+
+func (c *fixedClock) Now() time.Time { return c.now }
+
+func TestSessionSummaryGolden(t *testing.T) {
+	localizer := newTestLocalizer(t)
+	clock := &fixedClock{now: time.Date(2026, 3, 4, 8, 0, 0, 0, time.Local)}
+
+	gym := NewTerminalGym(localizer)
+	gym.setOutput(&bytes.Buffer{})
+	gym.layout = Layout{Width: 80, Height: 50}
+	gym.clock = clock
+	if err := gym.chooseExercise("buttock"); err != nil {
+		t.Fatal(err)
+	}
+	gym.useExercise(1, 0)
+	gym.startStep(0)
+
+	// Three reps, which set the pace; the session is then taken to have
+	// lasted a minute at double tempo with a 10s pause
+	for gym.workout.TotalProgress() < 3 {
+		gym.workout.Update(physicsStep)
+	}
+	clock.now = clock.now.Add(70 * time.Second)
+	gym.activeFor, gym.tempoSeconds = time.Minute, 120
+	gym.pausedFor, gym.stepPaused = 10*time.Second, 10*time.Second
+	gym.recordStep(clock.now)
+
+	yesterday := clock.now.AddDate(0, 0, -1)
+	history := []SessionRecord{
+		{Exercise: "buttock", Start: yesterday, End: yesterday.Add(time.Minute), Reps: 5},
+		gym.records[0],
+	}
+
+	var buf bytes.Buffer
+	gym.writeSummary(&buf, history, clock.now)
+	assertGolden(t, "session_summary", buf.String())
+}
+
+// Quitting while the next step is previewed leaves that step out of the
+// history and the summary
+func TestQuitDuringNextUp(t *testing.T) {
+	localizer := newTestLocalizer(t)
+	clock := &fixedClock{now: time.Date(2026, 3, 4, 8, 0, 0, 0, time.Local)}
+
+	var out bytes.Buffer
+	gym := NewTerminalGym(localizer)
+	gym.setOutput(&out)
+	gym.layout = Layout{Width: 80, Height: 50}
+	gym.clock = clock
+	gym.historyPath = filepath.Join(t.TempDir(), historyFile)
+	err := gym.useProgram(&Program{
+		ID:   "test",
+		Name: "test",
+		Steps: []ProgramStep{
+			{Exercise: "buttock", Reps: 1},
+			{Exercise: "meditation", Reps: 1},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	gym.startStep(0)
+	for !gym.workout.IsComplete() {
+		gym.workout.Update(physicsStep)
+	}
+	clock.now = clock.now.Add(time.Minute)
+	gym.recordStep(clock.now)
+	gym.startStep(1)
+	gym.nextUpLeft = nextUpDuration
+
+	clock.now = clock.now.Add(2 * time.Second)
+	gym.showSummary()
+
+	history, err := LoadHistory(gym.historyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].Exercise != "buttock" {
+		t.Errorf("history = %+v, want only the buttock step", history)
+	}
+	if heading := localizer.Tf("step_heading", 2, 2, localizer.T("meditation_name")); bytes.Contains(out.Bytes(), []byte(heading)) {
+		t.Errorf("summary lists the step that never started:\n%s", out.String())
+	}
+}
+
+func TestScreenDrawsOnlyChanges(t *testing.T) {
+	var out bytes.Buffer
+	screen := NewScreen(&out)
+
+	frame := &Frame{}
+	frame.Write([]byte("hello\nworld\n"))
+	screen.Draw(frame)
+
+	out.Reset()
+	frame = &Frame{}
+	frame.Write([]byte("hello\nwords\n"))
+	screen.Draw(frame)
+
+	if got, want := out.String(), "\033[2;4Hds"; got != want {
+		t.Errorf("diff output = %q, want %q", got, want)
+	}
+
+	out.Reset()
+	frame = &Frame{}
+	frame.Write([]byte("你好\n"))
+	screen.Draw(frame)
+
+	if got, want := out.String(), "\033[1;1H你好\033[K\033[2;1H\033[K"; got != want {
+		t.Errorf("diff output = %q, want %q", got, want)
+	}
+
+	// A change inside a styled run is redrawn in that style
+	out.Reset()
+	frame = &Frame{}
+	frame.Write([]byte(paint(true, styleTitle, "你们") + "\n"))
+	screen.Draw(frame)
+
+	if got, want := out.String(), "\033[1;1H"+styleTitle+"你们"+styleReset; got != want {
+		t.Errorf("diff output = %q, want %q", got, want)
+	}
+
+	out.Reset()
+	frame = &Frame{}
+	frame.Write([]byte(paint(true, styleTitle, "你好") + "\n"))
+	screen.Draw(frame)
+
+	if got, want := out.String(), "\033[1;3H"+styleTitle+"好"+styleReset; got != want {
+		t.Errorf("diff output = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// notifyResize does nothing on Windows, which has no SIGWINCH; the layout
+// is still read from the console when the session starts
+func notifyResize(c chan<- os.Signal) {}
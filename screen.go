@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Terminal control sequences used by the screen
+const (
+	enterAltScreen = "\033[?1049h"
+	leaveAltScreen = "\033[?1049l"
+	hideCursor     = "\033[?25l"
+	showCursor     = "\033[?25h"
+	clearAll       = "\033[H\033[2J"
+	clearToEOL     = "\033[K"
+)
+
+// cell is one glyph on screen, the number of columns it covers and the
+// style it is drawn in
+type cell struct {
+	text  string
+	width int
+	style string
+}
+
+// Frame collects the text of one screen update. It implements io.Writer
+// so frames can be built with fmt.Fprint and friends.
+type Frame struct {
+	buf strings.Builder
+}
+
+func (f *Frame) Write(p []byte) (int, error) {
+	return f.buf.Write(p)
+}
+
+// Lines returns the frame split into lines, without a trailing empty line
+func (f *Frame) Lines() []string {
+	text := strings.TrimSuffix(f.buf.String(), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// Screen renders frames into an in-memory cell grid and writes only the
+// cells that differ from the previous frame, positioned with cursor
+// movement, instead of clearing and reprinting the whole terminal.
+type Screen struct {
+	out    io.Writer
+	prev   [][]cell
+	active bool
+
+	// Frames are clipped to this size so nothing wraps; 0 means unlimited
+	width  int
+	height int
+}
+
+func NewScreen(out io.Writer) *Screen {
+	return &Screen{out: out}
+}
+
+// Resize sets the visible area and forces the next Draw to repaint
+// everything, since the terminal may have reflowed the old contents
+func (s *Screen) Resize(width, height int) {
+	s.width = width
+	s.height = height
+	if s.active {
+		s.prev = nil
+		fmt.Fprint(s.out, clearAll)
+	}
+}
+
+// Open switches to the alternate screen buffer and hides the cursor
+func (s *Screen) Open() {
+	if s.active {
+		return
+	}
+	s.active = true
+	s.prev = nil
+	fmt.Fprint(s.out, enterAltScreen+hideCursor+clearAll)
+}
+
+// Close restores the cursor and the normal screen buffer. It is safe to
+// call more than once.
+func (s *Screen) Close() {
+	if !s.active {
+		return
+	}
+	s.active = false
+	s.prev = nil
+	fmt.Fprint(s.out, showCursor+leaveAltScreen)
+}
+
+// Draw updates the terminal to show frame
+func (s *Screen) Draw(frame *Frame) {
+	lines := frame.Lines()
+	if s.height > 0 && len(lines) > s.height {
+		lines = lines[:s.height]
+	}
+	next := make([][]cell, len(lines))
+	for i, line := range lines {
+		next[i] = clip(toCells(line), s.width)
+	}
+
+	var out bytes.Buffer
+	for row := 0; row < max(len(next), len(s.prev)); row++ {
+		var old, cur []cell
+		if row < len(s.prev) {
+			old = s.prev[row]
+		}
+		if row < len(next) {
+			cur = next[row]
+		}
+		diffRow(&out, row, old, cur)
+	}
+	s.prev = next
+
+	if out.Len() > 0 {
+		s.out.Write(out.Bytes())
+	}
+}
+
+// diffRow writes the changes needed to turn old into cur on the given row
+func diffRow(out *bytes.Buffer, row int, old, cur []cell) {
+	// Skip the unchanged prefix
+	start := 0
+	for start < len(old) && start < len(cur) && old[start] == cur[start] {
+		start++
+	}
+	if start == len(old) && start == len(cur) {
+		return
+	}
+
+	// Skip the unchanged suffix when both rows are the same width after
+	// the prefix, so the cells after it are still in the same columns
+	end := len(cur)
+	tail := false
+	if rowWidth(old[start:]) == rowWidth(cur[start:]) {
+		oldEnd := len(old)
+		for end > start && oldEnd > start && old[oldEnd-1] == cur[end-1] {
+			end--
+			oldEnd--
+		}
+		tail = true
+	}
+
+	fmt.Fprintf(out, "\033[%d;%dH", row+1, rowWidth(cur[:start])+1)
+	style := ""
+	for _, c := range cur[start:end] {
+		if c.style != style {
+			if style != "" {
+				out.WriteString(styleReset)
+			}
+			out.WriteString(c.style)
+			style = c.style
+		}
+		out.WriteString(c.text)
+	}
+	if style != "" {
+		out.WriteString(styleReset)
+	}
+	if !tail {
+		out.WriteString(clearToEOL)
+	}
+}
+
+// toCells splits a line into glyph cells. Style escape sequences aren't
+// cells themselves; they set the style of the cells that follow.
+func toCells(line string) []cell {
+	var cells []cell
+	style := ""
+	for line != "" {
+		if seq, rest, ok := cutStyle(line); ok {
+			style = seq
+			if seq == styleReset || seq == "\033[m" {
+				style = ""
+			}
+			line = rest
+			continue
+		}
+		text := line
+		if i := strings.Index(line[1:], "\033["); i >= 0 {
+			text = line[:i+1]
+		}
+		line = line[len(text):]
+		for _, g := range graphemes(text) {
+			cells = append(cells, cell{text: g, width: graphemeWidth(g), style: style})
+		}
+	}
+	return cells
+}
+
+// clip drops the cells that would extend past width columns
+func clip(cells []cell, width int) []cell {
+	if width <= 0 {
+		return cells
+	}
+	used := 0
+	for i, c := range cells {
+		used += c.width
+		if used > width {
+			return cells[:i]
+		}
+	}
+	return cells
+}
+
+// rowWidth returns the number of columns a run of cells covers
+func rowWidth(cells []cell) int {
+	width := 0
+	for _, c := range cells {
+		width += c.width
+	}
+	return width
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// Defaults used when no --sets/--rest flags are given
+	defaultSets = 1
+	defaultRest = 45 * time.Second
+)
+
+// SetRunner wraps any Exercise in a set/rest structure such as
+// "3 sets of 15 with 45s rest". Each set runs the exercise until it reports
+// completion, then a rest countdown runs before the exercise is Reset for
+// the next set.
+type SetRunner struct {
+	Exercise Exercise
+	// ExerciseID is the registry ID of Exercise, used in the history
+	ExerciseID string
+	Sets       int
+	Rest       time.Duration
+	// TimeLimit ends the workout after this long; 0 means no limit
+	TimeLimit time.Duration
+	// Tempo scales exercise time; rests and the time limit run in real
+	// time. 0 means normal speed.
+	Tempo float64
+
+	elapsed  time.Duration
+	active   time.Duration
+	set      int
+	resting  bool
+	restLeft time.Duration
+	done     bool
+	// cutShort is set when the time limit ended a set partway through
+	cutShort bool
+
+	// Progress from sets that have already finished
+	completed int
+}
+
+func NewSetRunner(exercise Exercise, sets int, rest time.Duration) *SetRunner {
+	if sets < 1 {
+		sets = 1
+	}
+	return &SetRunner{
+		Exercise: exercise,
+		Sets:     sets,
+		Rest:     rest,
+	}
+}
+
+// Start resets the exercise and begins the first set
+func (sr *SetRunner) Start() {
+	sr.set = 1
+	sr.resting = false
+	sr.restLeft = 0
+	sr.done = false
+	sr.cutShort = false
+	sr.completed = 0
+	sr.elapsed = 0
+	sr.active = 0
+	sr.Exercise.Reset()
+}
+
+// Update advances either the rest countdown or the exercise by dt
+func (sr *SetRunner) Update(dt time.Duration) {
+	if sr.done {
+		return
+	}
+
+	sr.elapsed += dt
+	if sr.TimeLimit > 0 && sr.elapsed >= sr.TimeLimit {
+		if !sr.resting {
+			sr.completed += sr.Exercise.GetProgress()
+			sr.cutShort = true
+		}
+		sr.resting = false
+		sr.done = true
+		return
+	}
+
+	if sr.resting {
+		sr.restLeft -= dt
+		if sr.restLeft <= 0 {
+			sr.nextSet()
+		}
+		return
+	}
+
+	sr.active += dt
+	if sr.Tempo > 0 {
+		dt = time.Duration(float64(dt) * sr.Tempo)
+	}
+	sr.Exercise.Update(dt)
+	sr.checkSet()
+}
+
+// checkSet finishes the set once the exercise is complete
+func (sr *SetRunner) checkSet() {
+	if !sr.Exercise.IsComplete() {
+		return
+	}
+
+	sr.completed += sr.Exercise.GetProgress()
+	if sr.set >= sr.Sets {
+		sr.done = true
+		return
+	}
+
+	sr.restLeft = sr.Rest
+	if sr.restLeft <= 0 {
+		sr.nextSet()
+		return
+	}
+	sr.resting = true
+}
+
+// SkipPhase ends the rest early, or moves the exercise on to its next phase
+func (sr *SetRunner) SkipPhase() {
+	switch {
+	case sr.done:
+	case sr.resting:
+		sr.nextSet()
+	default:
+		sr.Exercise.SkipPhase()
+		sr.checkSet()
+	}
+}
+
+// RestartSet starts the current set over; progress made in it is dropped
+func (sr *SetRunner) RestartSet() {
+	if sr.done || sr.resting {
+		return
+	}
+	sr.Exercise.Reset()
+}
+
+func (sr *SetRunner) nextSet() {
+	sr.resting = false
+	sr.set++
+	sr.Exercise.Reset()
+}
+
+// IsComplete reports whether every set has been finished
+func (sr *SetRunner) IsComplete() bool {
+	return sr.done
+}
+
+// Resting reports whether the runner is between sets
+func (sr *SetRunner) Resting() bool {
+	return sr.resting
+}
+
+// RestRemaining returns the time left in the current rest interval
+func (sr *SetRunner) RestRemaining() time.Duration {
+	if !sr.resting {
+		return 0
+	}
+	return sr.restLeft
+}
+
+// Elapsed returns the time spent in the workout, rests included
+func (sr *SetRunner) Elapsed() time.Duration {
+	return sr.elapsed
+}
+
+// ActiveTime returns the time spent exercising, rests excluded
+func (sr *SetRunner) ActiveTime() time.Duration {
+	return sr.active
+}
+
+// Pace returns the reps (or breath cycles) per minute of exercising, or 0
+// before any time has passed
+func (sr *SetRunner) Pace() float64 {
+	if sr.active <= 0 {
+		return 0
+	}
+	return float64(sr.TotalProgress()) / sr.active.Minutes()
+}
+
+// CurrentSet returns the 1-based number of the set in progress
+func (sr *SetRunner) CurrentSet() int {
+	return sr.set
+}
+
+// CompletedSets returns the number of sets that have been finished. A
+// set the time limit cut short doesn't count.
+func (sr *SetRunner) CompletedSets() int {
+	if (sr.done && !sr.cutShort) || sr.resting {
+		return sr.set
+	}
+	return sr.set - 1
+}
+
+// TotalProgress returns reps (or breath cycles) across all sets so far
+func (sr *SetRunner) TotalProgress() int {
+	if sr.done || sr.resting {
+		return sr.completed
+	}
+	return sr.completed + sr.Exercise.GetProgress()
+}
+
+// GetCounter returns the exercise counter prefixed with the set number
+// when the workout has more than one set
+func (sr *SetRunner) GetCounter(localizer *Localizer) string {
+	counter := sr.Exercise.GetCounter()
+	if sr.Sets > 1 {
+		counter = fmt.Sprintf("%s · %s", localizer.Tf("set_counter", sr.set, sr.Sets), counter)
+	}
+	if sr.TimeLimit > 0 {
+		counter = fmt.Sprintf("%s · %s", counter, localizer.Tf("time_left", formatClock(sr.TimeLimit-sr.Elapsed())))
+	}
+	return counter
+}
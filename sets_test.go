@@ -0,0 +1,173 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newCalfRunner runs calf raises, whose reps take 5s each and are
+// counted 4.5s in, in sets of the given number of reps
+func newCalfRunner(t *testing.T, reps, sets int, rest time.Duration) *SetRunner {
+	t.Helper()
+	calf, err := LoadExerciseDefinition(filepath.Join("exercises", "calf_raises.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exercise := NewDefinedExercise(calf, newTestLocalizer(t))
+	exercise.SetTarget(reps)
+	runner := NewSetRunner(exercise, sets, rest)
+	runner.Start()
+	return runner
+}
+
+// checkRunner compares the state of a runner with what a test expects
+func checkRunner(t *testing.T, when string, sr *SetRunner, set, completedSets, progress int, resting, done bool) {
+	t.Helper()
+	if sr.CurrentSet() != set || sr.CompletedSets() != completedSets || sr.TotalProgress() != progress ||
+		sr.Resting() != resting || sr.IsComplete() != done {
+		t.Errorf("%s: set %d, %d sets and %d reps done, resting %v, complete %v; want set %d, %d, %d, %v, %v",
+			when, sr.CurrentSet(), sr.CompletedSets(), sr.TotalProgress(), sr.Resting(), sr.IsComplete(),
+			set, completedSets, progress, resting, done)
+	}
+}
+
+func TestSetRunnerSetsAndRest(t *testing.T) {
+	sr := newCalfRunner(t, 2, 2, 30*time.Second)
+	checkRunner(t, "start", sr, 1, 0, 0, false, false)
+
+	sr.Update(5 * time.Second)
+	checkRunner(t, "one rep in", sr, 1, 0, 1, false, false)
+
+	sr.Update(5 * time.Second)
+	checkRunner(t, "first set done", sr, 1, 1, 2, true, false)
+	if got := sr.RestRemaining(); got != 30*time.Second {
+		t.Errorf("rest remaining = %v, want 30s", got)
+	}
+
+	sr.Update(29 * time.Second)
+	checkRunner(t, "resting", sr, 1, 1, 2, true, false)
+	if got := sr.RestRemaining(); got != time.Second {
+		t.Errorf("rest remaining = %v, want 1s", got)
+	}
+
+	sr.Update(time.Second)
+	checkRunner(t, "second set", sr, 2, 1, 2, false, false)
+	if got := sr.Exercise.GetProgress(); got != 0 {
+		t.Errorf("exercise progress = %d after the rest, want it reset to 0", got)
+	}
+
+	sr.Update(10 * time.Second)
+	checkRunner(t, "all sets done", sr, 2, 2, 4, false, true)
+	if got := sr.Elapsed(); got != 50*time.Second {
+		t.Errorf("elapsed = %v, want 50s", got)
+	}
+
+	// Nothing moves once complete
+	sr.Update(time.Minute)
+	checkRunner(t, "after completion", sr, 2, 2, 4, false, true)
+}
+
+func TestSetRunnerWithoutRest(t *testing.T) {
+	sr := newCalfRunner(t, 1, 3, 0)
+	sr.Update(5 * time.Second)
+	checkRunner(t, "first set done", sr, 2, 1, 1, false, false)
+}
+
+func TestSetRunnerSkipPhase(t *testing.T) {
+	sr := newCalfRunner(t, 1, 2, time.Minute)
+
+	// A calf raise rep is counted once its third phase, lower, ends
+	for i := 0; i < 3; i++ {
+		sr.SkipPhase()
+	}
+	checkRunner(t, "phases skipped", sr, 1, 1, 1, true, false)
+
+	// Skipping the rest starts the next set straight away
+	sr.SkipPhase()
+	checkRunner(t, "rest skipped", sr, 2, 1, 1, false, false)
+
+	for i := 0; i < 3; i++ {
+		sr.SkipPhase()
+	}
+	checkRunner(t, "last set skipped", sr, 2, 2, 2, false, true)
+
+	sr.SkipPhase()
+	checkRunner(t, "skip after completion", sr, 2, 2, 2, false, true)
+}
+
+func TestSetRunnerRestartSet(t *testing.T) {
+	sr := newCalfRunner(t, 2, 2, time.Minute)
+
+	sr.Update(7 * time.Second)
+	sr.RestartSet()
+	checkRunner(t, "restarted", sr, 1, 0, 0, false, false)
+
+	sr.Update(10 * time.Second)
+	checkRunner(t, "first set done", sr, 1, 1, 2, true, false)
+
+	// Restarting does nothing during a rest
+	sr.RestartSet()
+	checkRunner(t, "restart during rest", sr, 1, 1, 2, true, false)
+	if got := sr.RestRemaining(); got != time.Minute {
+		t.Errorf("rest remaining = %v, want 1m", got)
+	}
+}
+
+func TestSetRunnerTimeLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     time.Duration
+		set       int
+		completed int
+		progress  int
+	}{
+		// The limit ends the first set one rep in
+		{"partway through a set", 7 * time.Second, 1, 0, 1},
+		// The first set finished, the limit ends the rest
+		{"during a rest", 20 * time.Second, 1, 1, 2},
+		// The limit ends the second set one rep in
+		{"partway through the second set", 47 * time.Second, 2, 1, 3},
+	}
+	for _, tt := range tests {
+		sr := newCalfRunner(t, 2, 3, 30*time.Second)
+		sr.TimeLimit = tt.limit
+		for !sr.IsComplete() {
+			sr.Update(time.Second)
+		}
+		checkRunner(t, tt.name, sr, tt.set, tt.completed, tt.progress, false, true)
+		if sr.Elapsed() != tt.limit {
+			t.Errorf("%s: elapsed = %v, want the limit %v", tt.name, sr.Elapsed(), tt.limit)
+		}
+	}
+}
+
+func TestSetRunnerTempo(t *testing.T) {
+	sr := newCalfRunner(t, 2, 1, 0)
+	sr.Tempo = 2
+
+	// Double tempo finishes two 5s reps in 5s
+	sr.Update(5 * time.Second)
+	checkRunner(t, "double tempo", sr, 1, 1, 2, false, true)
+}
+
+func TestSetRunnerPace(t *testing.T) {
+	sr := newCalfRunner(t, 2, 2, 30*time.Second)
+	if got := sr.Pace(); got != 0 {
+		t.Errorf("pace before starting = %v, want 0", got)
+	}
+	for !sr.IsComplete() {
+		sr.Update(time.Second)
+	}
+	// Four reps in 20s of exercising; the rest doesn't count
+	if sr.ActiveTime() != 20*time.Second || sr.Pace() != 12 {
+		t.Errorf("active %v at %v reps/min, want 20s at 12", sr.ActiveTime(), sr.Pace())
+	}
+
+	sr = newCalfRunner(t, 2, 1, 0)
+	sr.Tempo = 2
+	sr.Update(5 * time.Second)
+	if sr.Pace() != 24 {
+		t.Errorf("pace at double tempo = %v reps/min, want 24", sr.Pace())
+	}
+}
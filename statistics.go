@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// dailyDays and weeklyWeeks are how far back the minute charts go
+	dailyDays   = 7
+	weeklyWeeks = 4
+
+	// barWidth is the length of the longest bar in the minute charts
+	barWidth = 20
+)
+
+// Stats is everything the stats command reports, computed from the
+// history
+type Stats struct {
+	Sessions int
+	Total    time.Duration
+
+	// Exercises are in the order they were first recorded
+	Exercises []*ExerciseStats
+
+	// Time spent per local calendar day, keyed by dayKey
+	Days map[string]time.Duration
+
+	Today    time.Duration
+	ThisWeek time.Duration
+
+	CurrentStreak int
+	LongestStreak int
+
+	now time.Time
+}
+
+// ExerciseStats sums up the sessions of one exercise and keeps its
+// personal bests
+type ExerciseStats struct {
+	ID       string
+	Sessions int
+	Time     time.Duration
+	Reps     int
+	Cycles   int
+
+	BestReps    int
+	BestCycles  int
+	LongestTime time.Duration
+}
+
+// computeStats summarizes records as of now. Days are local calendar
+// days and weeks start on Monday.
+func computeStats(records []SessionRecord, now time.Time) *Stats {
+	stats := &Stats{Days: map[string]time.Duration{}, now: now}
+	byID := map[string]*ExerciseStats{}
+	today := startOfDay(now)
+	week := startOfWeek(now)
+
+	for _, r := range records {
+		d := r.Duration()
+		stats.Sessions++
+		stats.Total += d
+		stats.Days[dayKey(r.Start)] += d
+		if start := r.Start.Local(); !start.Before(today) {
+			stats.Today += d
+		}
+		if start := r.Start.Local(); !start.Before(week) {
+			stats.ThisWeek += d
+		}
+
+		e, ok := byID[r.Exercise]
+		if !ok {
+			e = &ExerciseStats{ID: r.Exercise}
+			byID[r.Exercise] = e
+			stats.Exercises = append(stats.Exercises, e)
+		}
+		e.Sessions++
+		e.Time += d
+		e.Reps += r.Reps
+		e.Cycles += r.Cycles
+		e.BestReps = max(e.BestReps, r.Reps)
+		e.BestCycles = max(e.BestCycles, r.Cycles)
+		e.LongestTime = max(e.LongestTime, d)
+	}
+
+	stats.CurrentStreak, stats.LongestStreak = streaks(stats.Days, today)
+	return stats
+}
+
+// streaks returns the current and the longest run of consecutive days
+// with at least one session. A streak is still current if its last day
+// was yesterday, since today isn't over yet.
+func streaks(days map[string]time.Duration, today time.Time) (current, longest int) {
+	if len(days) == 0 {
+		return 0, 0
+	}
+
+	// Walk from the earliest recorded day to today
+	first := today
+	for key := range days {
+		if day, err := time.ParseInLocation(dayLayout, key, time.Local); err == nil && day.Before(first) {
+			first = day
+		}
+	}
+
+	run := 0
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		if _, ok := days[dayKey(day)]; ok {
+			run++
+			longest = max(longest, run)
+		} else if !day.Equal(today) {
+			run = 0
+		}
+	}
+	return run, longest
+}
+
+const dayLayout = "2006-01-02"
+
+// dayKey identifies the local calendar day of t
+func dayKey(t time.Time) string {
+	return t.Local().Format(dayLayout)
+}
+
+// startOfDay returns local midnight on the day of t
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Local().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// startOfWeek returns local midnight on the Monday of the week of t
+func startOfWeek(t time.Time) time.Time {
+	day := startOfDay(t)
+	offset := (int(day.Weekday()) + 6) % 7 // days since Monday
+	return day.AddDate(0, 0, -offset)
+}
+
+// minutes rounds d to whole minutes
+func minutes(d time.Duration) int {
+	return int(d.Round(time.Minute) / time.Minute)
+}
+
+// writeStats prints the report: totals, recent minutes, streaks, totals
+// and bests per exercise, and the calendar heatmap
+func writeStats(w io.Writer, stats *Stats, localizer *Localizer, weeks int) {
+	fmt.Fprintln(w, localizer.Tn("stats_total", Args{"sessions": stats.Sessions, "time": formatClock(stats.Total)}))
+	fmt.Fprintln(w, localizer.Tf("stats_today", minutes(stats.Today), minutes(stats.ThisWeek)))
+	fmt.Fprintln(w, localizer.Tn("stats_streak", Args{"current": stats.CurrentStreak, "longest": stats.LongestStreak}))
+
+	// Minutes per day over the last week, and per week over the last month
+	today := startOfDay(stats.now)
+	var labels []string
+	var values []time.Duration
+	for i := dailyDays - 1; i >= 0; i-- {
+		day := today.AddDate(0, 0, -i)
+		labels = append(labels, weekdayName(day.Weekday(), localizer)+" "+day.Format("01-02"))
+		values = append(values, stats.Days[dayKey(day)])
+	}
+	fmt.Fprintln(w, "\n"+localizer.T("stats_daily"))
+	writeBars(w, labels, values, localizer)
+
+	labels, values = nil, nil
+	week := startOfWeek(stats.now)
+	for i := weeklyWeeks - 1; i >= 0; i-- {
+		start := week.AddDate(0, 0, -7*i)
+		var total time.Duration
+		for d := 0; d < 7; d++ {
+			total += stats.Days[dayKey(start.AddDate(0, 0, d))]
+		}
+		labels = append(labels, localizer.Tf("stats_week_of", start.Format("01-02")))
+		values = append(values, total)
+	}
+	fmt.Fprintln(w, "\n"+localizer.T("stats_weekly"))
+	writeBars(w, labels, values, localizer)
+
+	// Totals and personal bests per exercise
+	fmt.Fprintln(w, "\n"+localizer.T("stats_exercises"))
+	for _, e := range stats.Exercises {
+		fmt.Fprintln(w, exerciseName(e.ID, localizer))
+		fmt.Fprintln(w, "   "+localizer.Tn("stats_exercise", Args{"sessions": e.Sessions, "time": formatClock(e.Time)}))
+		if e.Reps > 0 {
+			fmt.Fprintln(w, "   "+localizer.Tn("stats_reps", Args{"total": e.Reps, "best": e.BestReps}))
+		}
+		if e.Cycles > 0 {
+			fmt.Fprintln(w, "   "+localizer.Tn("stats_cycles", Args{"total": e.Cycles, "best": e.BestCycles}))
+		}
+		fmt.Fprintln(w, "   "+localizer.Tf("stats_longest", formatClock(e.LongestTime)))
+	}
+
+	if weeks > 0 {
+		fmt.Fprintln(w, "\n"+localizer.Tn("stats_heatmap", Args{"weeks": weeks}))
+		writeHeatmap(w, stats.Days, stats.now, weeks, localizer)
+	}
+}
+
+// writeBars draws a horizontal bar per value, scaled to the largest one
+func writeBars(w io.Writer, labels []string, values []time.Duration, localizer *Localizer) {
+	labelWidth := 0
+	longest := time.Duration(0)
+	for i, label := range labels {
+		labelWidth = max(labelWidth, displayWidth(label))
+		longest = max(longest, values[i])
+	}
+
+	for i, label := range labels {
+		bar := 0
+		if longest > 0 {
+			bar = int(float64(barWidth) * float64(values[i]) / float64(longest))
+		}
+		if bar == 0 && values[i] > 0 {
+			bar = 1
+		}
+		padding := strings.Repeat(" ", labelWidth-displayWidth(label))
+		fmt.Fprintf(w, "   %s%s  %s%s\n", label, padding, strings.Repeat("█", bar)+strings.Repeat(" ", min(bar, 1)), localizer.Tf("stats_minutes", minutes(values[i])))
+	}
+}
+
+// weekdayName returns the localized short name of a weekday
+func weekdayName(day time.Weekday, localizer *Localizer) string {
+	return localizer.T(fmt.Sprintf("weekday_%d", int(day)))
+}
+
+const (
+	// defaultHeatmapWeeks is how many weeks the calendar heatmap shows
+	defaultHeatmapWeeks = 26
+
+	// heatmapCellWidth is the width of one day in the heatmap
+	heatmapCellWidth = 2
+)
+
+// heatmapLevels shade a day from no activity to the busiest quarter
+var heatmapLevels = []string{"·", "░", "▒", "▓", "█"}
+
+// heatmapLabelWidth is the width of the weekday labels left of the heatmap
+func heatmapLabelWidth(localizer *Localizer) int {
+	width := 0
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		width = max(width, displayWidth(weekdayName(day, localizer)))
+	}
+	return width + 1
+}
+
+// writeHeatmap draws a calendar of the last weeks, one column per week
+// and one row per weekday from Monday, shading each day by the time spent
+func writeHeatmap(w io.Writer, days map[string]time.Duration, now time.Time, weeks int, localizer *Localizer) {
+	today := startOfDay(now)
+	first := startOfWeek(now).AddDate(0, 0, -7*(weeks-1))
+	labelWidth := heatmapLabelWidth(localizer)
+
+	busiest := time.Duration(0)
+	for day := first; !day.After(today); day = day.AddDate(0, 0, 1) {
+		busiest = max(busiest, days[dayKey(day)])
+	}
+
+	// Month names above the first week of each month, where they fit
+	months := strings.Repeat(" ", labelWidth)
+	used := labelWidth
+	for col := 0; col < weeks; col++ {
+		monday := first.AddDate(0, 0, 7*col)
+		if col > 0 && monday.Month() == monday.AddDate(0, 0, -7).Month() {
+			continue
+		}
+		at := labelWidth + col*heatmapCellWidth
+		name := localizer.T(fmt.Sprintf("month_%d", int(monday.Month())))
+		if at < used || at+displayWidth(name) > labelWidth+weeks*heatmapCellWidth {
+			continue
+		}
+		months += strings.Repeat(" ", at-used) + name
+		used = at + displayWidth(name)
+	}
+	fmt.Fprintln(w, strings.TrimRight(months, " "))
+
+	for row := 0; row < 7; row++ {
+		label := weekdayName(first.AddDate(0, 0, row).Weekday(), localizer)
+		line := label + strings.Repeat(" ", labelWidth-displayWidth(label))
+		for col := 0; col < weeks; col++ {
+			day := first.AddDate(0, 0, 7*col+row)
+			if day.After(today) {
+				break
+			}
+			line += heatmapCell(days[dayKey(day)], busiest) + strings.Repeat(" ", heatmapCellWidth-1)
+		}
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+
+	fmt.Fprintln(w, "\n"+localizer.Tf("stats_legend", strings.Join(heatmapLevels, " ")))
+}
+
+// heatmapCell shades a day by its quarter of the busiest day
+func heatmapCell(d, busiest time.Duration) string {
+	if d <= 0 || busiest <= 0 {
+		return heatmapLevels[0]
+	}
+	level := int((4*d + busiest - 1) / busiest) // 1 to 4, rounded up
+	return heatmapLevels[min(level, len(heatmapLevels)-1)]
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// statsNow is a Wednesday evening; its week started on Monday 2 March
+var statsNow = time.Date(2026, 3, 4, 20, 0, 0, 0, time.Local)
+
+// session records minutes of an exercise starting at the given local day
+// and hour
+func session(day string, hour, minutes, reps int) SessionRecord {
+	start, err := time.ParseInLocation(dayLayout, day, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	start = start.Add(time.Duration(hour) * time.Hour)
+	return SessionRecord{
+		Exercise: "buttock",
+		Start:    start,
+		End:      start.Add(time.Duration(minutes) * time.Minute),
+		Reps:     reps,
+	}
+}
+
+func TestStreaks(t *testing.T) {
+	tests := []struct {
+		name             string
+		records          []SessionRecord
+		current, longest int
+	}{
+		{"no sessions", nil, 0, 0},
+		{"today", []SessionRecord{session("2026-03-04", 8, 5, 10)}, 1, 1},
+		{"today missing", []SessionRecord{
+			session("2026-03-02", 8, 5, 10),
+			session("2026-03-03", 8, 5, 10),
+		}, 2, 2},
+		{"last session two days ago", []SessionRecord{
+			session("2026-03-01", 8, 5, 10),
+			session("2026-03-02", 8, 5, 10),
+		}, 0, 2},
+		{"gap before the current streak", []SessionRecord{
+			session("2026-02-20", 8, 5, 10),
+			session("2026-02-21", 8, 5, 10),
+			session("2026-02-22", 8, 5, 10),
+			session("2026-02-23", 8, 5, 10),
+			session("2026-03-03", 8, 5, 10),
+			session("2026-03-04", 8, 5, 10),
+		}, 2, 4},
+		{"several sessions on one day", []SessionRecord{
+			session("2026-03-03", 7, 5, 10),
+			session("2026-03-03", 12, 5, 10),
+			session("2026-03-03", 21, 5, 10),
+			session("2026-03-04", 8, 5, 10),
+		}, 2, 2},
+		{"out of order", []SessionRecord{
+			session("2026-03-04", 8, 5, 10),
+			session("2026-02-28", 8, 5, 10),
+			session("2026-03-03", 8, 5, 10),
+		}, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := computeStats(tt.records, statsNow)
+			if stats.CurrentStreak != tt.current || stats.LongestStreak != tt.longest {
+				t.Errorf("streaks = %d current, %d longest, want %d, %d", stats.CurrentStreak, stats.LongestStreak, tt.current, tt.longest)
+			}
+		})
+	}
+}
+
+func TestComputeStatsBuckets(t *testing.T) {
+	records := []SessionRecord{
+		session("2026-03-01", 9, 30, 12),  // Sunday, last week
+		session("2026-03-02", 9, 20, 20),  // Monday, this week
+		session("2026-03-03", 23, 40, 15), // yesterday, ending after midnight
+		session("2026-03-04", 7, 10, 8),
+		session("2026-03-04", 18, 5, 4),
+	}
+	records[4].Exercise = "meditation"
+	records[4].Reps, records[4].Cycles = 0, 4
+	records[2].Paused = jsonDuration(10 * time.Minute)
+
+	stats := computeStats(records, statsNow)
+	if stats.Sessions != 5 {
+		t.Errorf("Sessions = %d, want 5", stats.Sessions)
+	}
+	if want := 95 * time.Minute; stats.Total != want {
+		t.Errorf("Total = %v, want %v", stats.Total, want)
+	}
+	if want := 15 * time.Minute; stats.Today != want {
+		t.Errorf("Today = %v, want %v", stats.Today, want)
+	}
+	if want := 65 * time.Minute; stats.ThisWeek != want {
+		t.Errorf("ThisWeek = %v, want %v", stats.ThisWeek, want)
+	}
+	if want := 30 * time.Minute; stats.Days["2026-03-03"] != want {
+		t.Errorf("Days[2026-03-03] = %v, want %v, counted on the day it started", stats.Days["2026-03-03"], want)
+	}
+	if want := 15 * time.Minute; stats.Days["2026-03-04"] != want {
+		t.Errorf("Days[2026-03-04] = %v, want %v", stats.Days["2026-03-04"], want)
+	}
+
+	if len(stats.Exercises) != 2 || stats.Exercises[0].ID != "buttock" || stats.Exercises[1].ID != "meditation" {
+		t.Fatalf("Exercises = %v, want buttock then meditation", stats.Exercises)
+	}
+	buttock := stats.Exercises[0]
+	if buttock.Sessions != 4 || buttock.Reps != 55 || buttock.BestReps != 20 || buttock.LongestTime != 30*time.Minute {
+		t.Errorf("buttock = %+v, want 4 sessions, 55 reps, best 20, longest 30m", *buttock)
+	}
+	if meditation := stats.Exercises[1]; meditation.Cycles != 4 || meditation.BestCycles != 4 {
+		t.Errorf("meditation = %+v, want 4 cycles", *meditation)
+	}
+}
+
+func TestHeatmapCell(t *testing.T) {
+	tests := []struct {
+		d, busiest time.Duration
+		want       string
+	}{
+		{0, time.Hour, "·"},
+		{time.Minute, 0, "·"},
+		{time.Second, time.Hour, "░"},
+		{15 * time.Minute, time.Hour, "░"},
+		{16 * time.Minute, time.Hour, "▒"},
+		{30 * time.Minute, time.Hour, "▒"},
+		{45 * time.Minute, time.Hour, "▓"},
+		{46 * time.Minute, time.Hour, "█"},
+		{time.Hour, time.Hour, "█"},
+	}
+	for _, tt := range tests {
+		if got := heatmapCell(tt.d, tt.busiest); got != tt.want {
+			t.Errorf("heatmapCell(%v, %v) = %s, want %s", tt.d, tt.busiest, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// readHistory loads the recorded sessions. Unreadable lines are reported
+// as a warning; the readable ones are still used.
+func readHistory(localizer *Localizer) ([]SessionRecord, error) {
+	path, err := historyPath()
+	if err != nil {
+		return nil, err
+	}
+	records, err := LoadHistory(path)
+	if err != nil && records == nil {
+		return nil, err
+	}
+	if err != nil {
+		warn(localizer.T("history_warning"), err)
+	}
+	return records, nil
+}
+
+// exerciseName returns the localized name of a recorded exercise, or its
+// ID if it is no longer registered
+func exerciseName(id string, localizer *Localizer) string {
+	if info, ok := FindExercise(id); ok {
+		return info.New(localizer).GetName()
+	}
+	return id
+}
+
+// statsCommand summarizes the recorded sessions
+func statsCommand(localizer *Localizer, args []string) error {
+	fs := newFlagSet("stats", localizer)
+	weeks := fs.Int("weeks", defaultHeatmapWeeks, localizer.T("flag_weeks"))
+	fs.Parse(args)
+
+	loadDefinitions(localizer)
+	records, err := readHistory(localizer)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Println(localizer.T("history_empty"))
+		return nil
+	}
+
+	// Keep the heatmap within the terminal
+	layout := terminalLayout(os.Stdout)
+	maxWeeks := (layout.Width - heatmapLabelWidth(localizer)) / heatmapCellWidth
+	writeStats(os.Stdout, computeStats(records, time.Now()), localizer, min(*weeks, maxWeeks))
+	return nil
+}
+
+// historyCommand lists the most recent sessions, newest first
+func historyCommand(localizer *Localizer, args []string) error {
+	fs := newFlagSet("history", localizer)
+	limit := fs.Int("limit", 20, localizer.T("flag_limit"))
+	fs.Parse(args)
+
+	loadDefinitions(localizer)
+	records, err := readHistory(localizer)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Println(localizer.T("history_empty"))
+		return nil
+	}
+
+	for i := len(records) - 1; i >= 0 && (*limit <= 0 || len(records)-i <= *limit); i-- {
+		r := records[i]
+		status := localizer.T("history_completed")
+		if !r.Completed {
+			status = localizer.T("history_aborted")
+		}
+		line := fmt.Sprintf("%s  %s  %s  %s", r.Start.Local().Format("2006-01-02 15:04"), status, formatClock(r.Duration()), exerciseName(r.Exercise, localizer))
+		if r.Reps > 0 {
+			line += " · " + localizer.Tn("history_reps", Args{"count": r.Reps})
+		}
+		if r.Cycles > 0 {
+			line += " · " + localizer.Tn("history_cycles", Args{"count": r.Cycles})
+		}
+		if r.Pattern != "" {
+			line += " · " + r.Pattern
+		}
+		fmt.Println(line)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// writeSummary draws the end-of-session summary: how long the session
+// took, how much of it was paused, the average tempo if it was changed, the
+// streak, and per step the progress, the pace and how the progress compares
+// with the last session of the same exercise.
+// history is every recorded session including this one; without it the
+// streak and the comparisons are left out.
+func (tg *TerminalGym) writeSummary(w io.Writer, history []SessionRecord, now time.Time) {
+	title := tg.localizer.T("workout_complete")
+	if tg.program != nil {
+		title = tg.localizer.Tf("program_complete", tg.localizer.T(tg.program.Name))
+	} else if info, ok := FindExercise(tg.exerciseID); ok && info.Category == categoryMeditation {
+		title = tg.localizer.T("meditation_complete")
+	}
+	subtitle := tg.localizer.T("keep_work")
+	if tg.workout.IsComplete() && tg.nextStep() == nil {
+		subtitle = tg.localizer.T("target_reached")
+	}
+	tg.header(w, title, subtitle, false)
+
+	var total time.Duration
+	for _, record := range tg.records {
+		total += record.Duration()
+	}
+	tempo := tg.tempo
+	if tg.activeFor > 0 {
+		tempo = tg.tempoSeconds / tg.activeFor.Seconds()
+	}
+	lines := []string{
+		tg.localizer.Tf("summary_time", formatClock(total)),
+		tg.localizer.Tf("summary_paused", formatClock(tg.pausedFor)),
+	}
+	// Like the status line, the tempo is only mentioned once it changed
+	if math.Abs(tempo-1) >= 0.005 {
+		lines = append(lines, tg.localizer.Tf("summary_tempo", tempo))
+	}
+	if history != nil {
+		lines = append(lines, tg.localizer.Tn("summary_streak", Args{"days": computeStats(history, now).CurrentStreak}))
+	}
+
+	// Steps that were never started are left out, including one still in
+	// its "next up" preview
+	started := tg.stepIndex + 1
+	if tg.nextUpLeft > 0 {
+		started--
+	}
+	for i, step := range tg.steps[:started] {
+		lines = append(lines, "")
+		indent := ""
+		if len(tg.steps) > 1 {
+			lines = append(lines, tg.localizer.Tf("step_heading", i+1, len(tg.steps), step.Exercise.GetName()))
+			indent = "   "
+		}
+		if step.Sets > 1 {
+			lines = append(lines, indent+tg.localizer.Tf("sets_summary", step.CompletedSets(), step.Sets))
+		}
+		target := step.Exercise.GetTarget() * step.Sets
+		lines = append(lines, indent+withTarget(tg.localizer.Tf("progress_summary", step.TotalProgress()), target))
+		if step.TotalProgress() > 0 {
+			pace := "summary_pace"
+			if _, ok := step.Exercise.(*MeditationExercise); ok {
+				pace = "summary_pace_breaths"
+			}
+			lines = append(lines, indent+tg.localizer.Tf(pace, step.Pace()))
+		}
+		if i < len(tg.records) && history != nil {
+			lines = append(lines, indent+tg.comparison(tg.records[i], history))
+		}
+	}
+
+	for _, line := range tg.layout.block(lines...) {
+		fmt.Fprintln(w, strings.TrimRight(line, " "))
+	}
+	fmt.Fprintln(w, "\n"+tg.layout.rule("=")+"\n")
+}
+
+// comparison describes how a recorded step compares with the last
+// session of the same exercise before this one
+func (tg *TerminalGym) comparison(record SessionRecord, history []SessionRecord) string {
+	start := tg.records[0].Start
+	var last *SessionRecord
+	for i := range history {
+		if history[i].Exercise == record.Exercise && history[i].Start.Before(start) {
+			last = &history[i]
+		}
+	}
+	if last == nil {
+		return tg.localizer.T("summary_first")
+	}
+
+	diff := record.Reps + record.Cycles - last.Reps - last.Cycles
+	switch {
+	case diff > 0:
+		return tg.localizer.Tn("summary_more", Args{"count": diff})
+	case diff < 0:
+		return tg.localizer.Tn("summary_fewer", Args{"count": -diff})
+	}
+	return tg.localizer.T("summary_same")
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	localesDir      = "locales"
+	exercisesDir    = "exercises"
+	programsDir     = "programs"
+	referenceLocale = "en"
+)
+
+// validateCommand checks the locale, exercise definition and program
+// files, printing one line per file
+func validateCommand(localizer *Localizer, args []string) error {
+	fs := newFlagSet("validate", localizer)
+	fs.Parse(args)
+
+	var files fileReport
+	report := files.add
+
+	fmt.Println(localizer.T("validate_locales"))
+	validateLocales(report)
+
+	// Exercise definitions, which are registered so programs can use them
+	fmt.Println("\n" + localizer.T("validate_exercises"))
+	var definitions []*ExerciseDefinition
+	onDisk := map[string]bool{}
+	definitionPaths, err := dataFiles(exerciseDirs())
+	if err != nil {
+		report(exercisesDir, err)
+	}
+	for _, file := range definitionPaths {
+		def, err := LoadExerciseDefinition(file)
+		if err == nil {
+			definitions = append(definitions, def)
+			onDisk[def.ID] = true
+		}
+		report(file, err)
+	}
+	for _, file := range embeddedFiles(embeddedExercises, exercisesDir) {
+		def, err := loadEmbeddedDefinition(file)
+		if err == nil && !onDisk[def.ID] {
+			definitions = append(definitions, def)
+		}
+		report(file+" ("+builtinSource+")", err)
+	}
+	if err := RegisterDefinitions(definitions); err != nil {
+		report(localizer.T("validate_exercise_ids"), err)
+	}
+
+	// Programs, including the built-in ones, must name known exercises
+	// and breathing patterns
+	fmt.Println("\n" + localizer.T("validate_programs"))
+	gym := NewTerminalGym(localizer)
+	for _, program := range builtinPrograms {
+		report(program.ID, gym.useProgram(program))
+	}
+	programPaths, err := dataFiles(programDirs())
+	if err != nil {
+		report(programsDir, err)
+	}
+	for _, file := range programPaths {
+		program, err := LoadProgram(file)
+		if err == nil {
+			err = gym.useProgram(program)
+		}
+		report(file, err)
+	}
+	for _, file := range embeddedFiles(embeddedPrograms, programsDir) {
+		program, err := loadEmbeddedProgram(file)
+		if err == nil {
+			err = gym.useProgram(program)
+		}
+		report(file+" ("+builtinSource+")", err)
+	}
+
+	return files.result(localizer)
+}
+
+// validateLocalesCommand checks only the locale files, for translators
+func validateLocalesCommand(localizer *Localizer, args []string) error {
+	fs := newFlagSet("validate-locales", localizer)
+	fs.Parse(args)
+
+	var files fileReport
+	validateLocales(files.add)
+	return files.result(localizer)
+}
+
+// fileReport prints a line per checked file and counts the failures
+type fileReport struct {
+	failed int
+}
+
+func (r *fileReport) add(name string, err error) {
+	if err != nil {
+		r.failed++
+		fmt.Printf("❌ %s\n%s\n", name, indentLines(err.Error(), "   "))
+		return
+	}
+	fmt.Printf("✅ %s\n", name)
+}
+
+// result prints the outcome, which is an error if any file failed
+func (r *fileReport) result(localizer *Localizer) error {
+	if r.failed > 0 {
+		return fmt.Errorf("\n%s", localizer.Tn("validate_failed", Args{"count": r.failed}))
+	}
+	fmt.Println("\n" + localizer.T("validate_ok"))
+	return nil
+}
+
+// validateLocales checks the shipped locales against the reference
+// locale, then the user's translation files. User files only override
+// keys, so they may leave some out.
+func validateLocales(report func(name string, err error)) {
+	referenceFile := path.Join(localesDir, referenceLocale+".json")
+	reference, err := readLocaleFile(embeddedLocales, referenceFile)
+	if err != nil {
+		report(referenceFile, err)
+		return
+	}
+	report(referenceFile, validateLocaleFile(embeddedLocales, referenceFile, reference, true))
+	entries, _ := embeddedLocales.ReadDir(localesDir)
+	for _, entry := range entries {
+		if file := path.Join(localesDir, entry.Name()); file != referenceFile {
+			report(file, validateLocaleFile(embeddedLocales, file, reference, true))
+		}
+	}
+	if dir, err := localeOverrideDir(); err == nil {
+		for _, file := range jsonFiles([]string{dir}) {
+			report(file, validateLocaleFile(os.DirFS(dir), filepath.Base(file), reference, false))
+		}
+	}
+}
+
+// jsonFiles lists the *.json files in dirs; missing directories are skipped
+func jsonFiles(dirs []string) []string {
+	var files []string
+	for _, dir := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+		sort.Strings(matches)
+		files = append(files, matches...)
+	}
+	return files
+}
+
+// readLocaleFile parses a locale file into its translations
+func readLocaleFile(fsys fs.FS, path string) (map[string]string, error) {
+	data, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read translation file %s: %w", path, err)
+	}
+	translations := map[string]string{}
+	if err := json.Unmarshal(data, &translations); err != nil {
+		return nil, fmt.Errorf("failed to parse translation file %s: %w", path, err)
+	}
+	return translations, nil
+}
+
+// validateLocaleFile checks that a locale has only keys of the reference
+// locale and, if complete is set, all of them, and that each message is
+// well formed and takes the same arguments as the reference message
+func validateLocaleFile(fsys fs.FS, path string, reference map[string]string, complete bool) error {
+	translations, err := readLocaleFile(fsys, path)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, key := range sortedKeys(reference) {
+		if _, ok := translations[key]; !ok && complete {
+			errs = append(errs, fmt.Errorf("missing key %q", key))
+		}
+	}
+	for _, key := range sortedKeys(translations) {
+		want, ok := reference[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown key %q", key))
+			continue
+		}
+		if err := checkPlaceholders(want, translations[key]); err != nil {
+			errs = append(errs, fmt.Errorf("key %q %w", key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// formatVerb matches a fmt verb such as %d, %-5s or %%
+var formatVerb = regexp.MustCompile(`%[-+# 0]*(\d+|\*)?(\.(\d+|\*))?[a-zA-Z%]`)
+
+// checkPlaceholders compares what a translation takes with what the
+// reference message takes. Between fmt templates the verbs must match one
+// for one. A message in the message syntax must use the same arguments,
+// which for a fmt template are {0}, {1}, ... by position.
+func checkPlaceholders(reference, translation string) error {
+	if !isMessageFormat(reference) && !isMessageFormat(translation) {
+		if want, got := formatVerbs(reference), formatVerbs(translation); !slices.Equal(got, want) {
+			return fmt.Errorf("has verbs %s, want %s", describePlaceholders(got), describePlaceholders(want))
+		}
+		return nil
+	}
+	want, err := placeholders(reference)
+	if err != nil {
+		return fmt.Errorf("has a malformed reference message: %w", err)
+	}
+	got, err := placeholders(translation)
+	if err != nil {
+		return fmt.Errorf("is malformed: %w", err)
+	}
+	if !slices.Equal(got, want) {
+		return fmt.Errorf("has arguments %s, want %s", describePlaceholders(got), describePlaceholders(want))
+	}
+	return nil
+}
+
+// formatVerbs lists the fmt verbs of a template, leaving out %%
+func formatVerbs(template string) []string {
+	var verbs []string
+	for _, verb := range formatVerb.FindAllString(template, -1) {
+		if verb != "%%" {
+			verbs = append(verbs, verb)
+		}
+	}
+	return verbs
+}
+
+// placeholders lists the argument names of a message, sorted
+func placeholders(template string) ([]string, error) {
+	if isMessageFormat(template) {
+		names, err := messageArguments(template)
+		if err != nil {
+			return nil, err
+		}
+		for i, name := range names {
+			names[i] = "{" + name + "}"
+		}
+		return names, nil
+	}
+	names := make([]string, len(formatVerbs(template)))
+	for i := range names {
+		names[i] = "{" + strconv.Itoa(i) + "}"
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func describePlaceholders(placeholders []string) string {
+	if len(placeholders) == 0 {
+		return "none"
+	}
+	return strings.Join(placeholders, " ")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// indentLines prefixes every line of s
+func indentLines(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
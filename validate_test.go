@@ -0,0 +1,232 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// allowedLiterals are strings in the source that are printed without a
+// translation on purpose
+var allowedLiterals = map[string]bool{
+	"terminal-gym %s\n":                      true, // the version, with the program name
+	"| ID | Name | Category | Description |": true, // the Markdown table for the README
+}
+
+// diagnosticDecls are the declarations whose errors describe the contents
+// of a file rather than what the user typed: definition, program, config
+// and locale files, and the message syntax. Like compiler errors they are
+// in English. Errors returned for command-line arguments must still be
+// translated.
+var diagnosticDecls = map[string]bool{
+	"ExerciseDefinition.Validate": true,
+	"checkCounterLabel":           true,
+	"Program.Validate":            true,
+	"jsonDuration":                true, // durations in program files
+	"RegisterExercise":            true, // two exercises with one ID
+	"settings":                    true, // values from the config file or environment
+	"parseColorMode":              true,
+	"LoadConfig":                  true,
+	"parseConfigJSON":             true,
+	"parseConfigTOML":             true,
+	"parseTOMLValue":              true,
+	"SetConfigValue":              true, // configCommand checks the arguments first
+	"messageParser":               true,
+	"validateLocaleFile":          true,
+	"checkPlaceholders":           true,
+}
+
+// declName names a top-level declaration for diagnosticDecls: a function,
+// the receiver type of a method and the method, or the first variable
+func declName(decl ast.Decl) []string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		if d.Recv == nil {
+			return []string{d.Name.Name}
+		}
+		recv := d.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		if ident, ok := recv.(*ast.Ident); ok {
+			return []string{ident.Name, ident.Name + "." + d.Name.Name}
+		}
+	case *ast.GenDecl:
+		if len(d.Specs) > 0 {
+			if spec, ok := d.Specs[0].(*ast.ValueSpec); ok {
+				return []string{spec.Names[0].Name}
+			}
+		}
+	}
+	return nil
+}
+
+// proseLiteral matches strings that read as text for the user: a word
+// and a space
+var proseLiteral = regexp.MustCompile(`[A-Za-z]{3,}.* |.* [A-Za-z]{3,}`)
+
+// parseSource parses the non-test Go files of the package
+func parseSource(t *testing.T) (*token.FileSet, []*ast.File) {
+	t.Helper()
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	var parsed []*ast.File
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, f)
+	}
+	return fset, parsed
+}
+
+func readReferenceLocale(t *testing.T) map[string]string {
+	t.Helper()
+	reference, err := readLocaleFile(embeddedLocales, path.Join(localesDir, referenceLocale+".json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reference
+}
+
+func TestShippedLocales(t *testing.T) {
+	reference := readReferenceLocale(t)
+	entries, err := embeddedLocales.ReadDir(localesDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		file := path.Join(localesDir, entry.Name())
+		if err := validateLocaleFile(embeddedLocales, file, reference, true); err != nil {
+			t.Errorf("%s:\n%v", file, err)
+		}
+	}
+}
+
+// TestSourceKeysExist checks that every key passed to T, Tf or Tn as a
+// literal is in the reference locale. Keys built at run time, such as
+// "cmd_"+name+"_summary", are not checked.
+func TestSourceKeysExist(t *testing.T) {
+	reference := readReferenceLocale(t)
+	fset, files := parseSource(t)
+	for _, f := range files {
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "T" && sel.Sel.Name != "Tf" && sel.Sel.Name != "Tn") {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			key, _ := strconv.Unquote(lit.Value)
+			if _, ok := reference[key]; !ok {
+				t.Errorf("%s: key %q is not in %s.json", fset.Position(lit.Pos()), key, referenceLocale)
+			}
+			return true
+		})
+	}
+}
+
+// TestNoUntranslatedLiterals looks for text in the source that is shown
+// to the user without going through the localizer. I/O errors wrapped
+// with their file, file diagnostics, struct tags and imports are left alone.
+func TestNoUntranslatedLiterals(t *testing.T) {
+	fset, files := parseSource(t)
+	var decls []ast.Decl
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			if !slices.ContainsFunc(declName(decl), func(name string) bool { return diagnosticDecls[name] }) {
+				decls = append(decls, decl)
+			}
+		}
+	}
+	for _, decl := range decls {
+		ast.Inspect(decl, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.ImportSpec:
+				return false
+			case *ast.Field:
+				return n.Tag == nil
+			case *ast.CallExpr:
+				if wrapsError(n) {
+					return false
+				}
+			case *ast.BasicLit:
+				if n.Kind != token.STRING || strings.HasPrefix(n.Value, "`") {
+					// Raw strings hold the ASCII art
+					return false
+				}
+				text, _ := strconv.Unquote(n.Value)
+				if proseLiteral.MatchString(text) && !allowedLiterals[text] {
+					t.Errorf("%s: %q is not translated", fset.Position(n.Pos()), text)
+				}
+			}
+			return true
+		})
+	}
+}
+
+func TestCheckPlaceholders(t *testing.T) {
+	tests := []struct {
+		reference, translation string
+		ok                     bool
+	}{
+		{"Rep: %d", "次数：%d", true},
+		{"Rep: %d", "次数：%s", false},
+		{"%s of %d", "%s / %d", true},
+		{"%s of %d", "%d / %s", false},
+		{"100%% done", "完成", true},
+		{"Starting in %d...", "{0, plural, one {# second} other {# seconds}}", true},
+		{"Starting in %d...", "{1} seconds", false},
+		{"{count, plural, one {# rep} other {# reps}}", "{count} 次", true},
+		{"{count, plural, one {# rep} other {# reps}}", "{n} 次", false},
+		{"{a} and {b}", "{b} 和 {a}", true},
+		{"{count, plural, one {# rep} other {# reps}}", "{count, plural, one {# rep}", false},
+		{"{count, plural, one {# rep} other {# reps}}", "%d 次", false},
+	}
+	for _, tt := range tests {
+		err := checkPlaceholders(tt.reference, tt.translation)
+		if (err == nil) != tt.ok {
+			t.Errorf("checkPlaceholders(%q, %q) = %v, want ok %v", tt.reference, tt.translation, err, tt.ok)
+		}
+	}
+}
+
+// ioContext matches the formats that only say which file or directory an
+// underlying error happened on
+var ioContext = regexp.MustCompile(`^(failed to|finding the|invalid) [^%]*(%s)?: %w$`)
+
+// wrapsError reports whether a call is fmt.Errorf wrapping an error with
+// I/O or file context, such as the file a read failed on. Other context
+// is shown to the user and must be translated.
+func wrapsError(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Errorf" || len(call.Args) == 0 {
+		return false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return false
+	}
+	format, _ := strconv.Unquote(lit.Value)
+	return ioContext.MatchString(format)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	zeroWidthJoiner = '\u200D'
+	textSelector    = '\uFE0E' // VS15: request text presentation
+	emojiSelector   = '\uFE0F' // VS16: request emoji presentation
+)
+
+// wideRanges lists code points that occupy two terminal columns: East
+// Asian Wide/Fullwidth characters and emoji with default emoji presentation
+var wideRanges = []struct{ lo, hi rune }{
+	{0x1100, 0x115F}, {0x231A, 0x231B}, {0x2329, 0x232A}, {0x23E9, 0x23EC},
+	{0x23F0, 0x23F0}, {0x23F3, 0x23F3}, {0x25FD, 0x25FE}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267F, 0x267F}, {0x2693, 0x2693}, {0x26A1, 0x26A1},
+	{0x26AA, 0x26AB}, {0x26BD, 0x26BE}, {0x26C4, 0x26C5}, {0x26CE, 0x26CE},
+	{0x26D4, 0x26D4}, {0x26EA, 0x26EA}, {0x26F2, 0x26F3}, {0x26F5, 0x26F5},
+	{0x26FA, 0x26FA}, {0x26FD, 0x26FD}, {0x2705, 0x2705}, {0x270A, 0x270B},
+	{0x2728, 0x2728}, {0x274C, 0x274C}, {0x274E, 0x274E}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27B0, 0x27B0}, {0x27BF, 0x27BF},
+	{0x2B1B, 0x2B1C}, {0x2B50, 0x2B50}, {0x2B55, 0x2B55}, {0x2E80, 0x303E},
+	{0x3041, 0x33FF}, {0x3400, 0x4DBF}, {0x4E00, 0x9FFF}, {0xA000, 0xA4CF},
+	{0xA960, 0xA97F}, {0xAC00, 0xD7A3}, {0xF900, 0xFAFF}, {0xFE10, 0xFE19},
+	{0xFE30, 0xFE6F}, {0xFF00, 0xFF60}, {0xFFE0, 0xFFE6}, {0x1F004, 0x1F004},
+	{0x1F0CF, 0x1F0CF}, {0x1F18E, 0x1F18E}, {0x1F191, 0x1F19A}, {0x1F200, 0x1F202},
+	{0x1F210, 0x1F23B}, {0x1F240, 0x1F248}, {0x1F250, 0x1F251}, {0x1F260, 0x1F265},
+	{0x1F300, 0x1F64F}, {0x1F680, 0x1F6FF}, {0x1F7E0, 0x1F7EB}, {0x1F90C, 0x1F9FF},
+	{0x1FA70, 0x1FAFF}, {0x20000, 0x2FFFD}, {0x30000, 0x3FFFD},
+}
+
+// runeWidth returns the number of columns a single code point occupies
+func runeWidth(r rune) int {
+	switch {
+	case r == 0:
+		return 0
+	case r < 0x20 || (r >= 0x7F && r < 0xA0):
+		return 0
+	case r < 0x1100:
+		if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) {
+			return 0
+		}
+		return 1
+	case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r), unicode.Is(unicode.Cf, r):
+		return 0
+	case r >= 0xFE00 && r <= 0xFE0F: // variation selectors
+		return 0
+	case r >= 0x1F3FB && r <= 0x1F3FF: // skin tone modifiers
+		return 0
+	}
+
+	for _, wr := range wideRanges {
+		if r < wr.lo {
+			break
+		}
+		if r <= wr.hi {
+			return 2
+		}
+	}
+	return 1
+}
+
+// isRegionalIndicator reports whether r is half of a flag emoji
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1F1E6 && r <= 0x1F1FF
+}
+
+// graphemes splits s into the clusters a terminal draws as one glyph: a
+// base character plus trailing combining marks, variation selectors, skin
+// tone modifiers and zero-width-joiner sequences. Flags (pairs of regional
+// indicators) are kept together.
+func graphemes(s string) []string {
+	var clusters []string
+	start := 0
+	joined := false
+	prev := rune(-1)
+
+	for i, r := range s {
+		if i > start {
+			extend := joined || runeWidth(r) == 0 && r >= 0x20 ||
+				isRegionalIndicator(prev) && isRegionalIndicator(r) && utf8.RuneCountInString(s[start:i]) == 1
+			if !extend {
+				clusters = append(clusters, s[start:i])
+				start = i
+			}
+		}
+		joined = r == zeroWidthJoiner
+		prev = r
+	}
+	if start < len(s) {
+		clusters = append(clusters, s[start:])
+	}
+	return clusters
+}
+
+// graphemeWidth returns the columns a single cluster occupies
+func graphemeWidth(g string) int {
+	base, _ := utf8.DecodeRuneInString(g)
+	width := runeWidth(base)
+	if isRegionalIndicator(base) {
+		return 2
+	}
+
+	for _, r := range g[utf8.RuneLen(base):] {
+		switch r {
+		case emojiSelector, zeroWidthJoiner:
+			if width > 0 {
+				width = 2
+			}
+		case textSelector:
+			if width > 0 {
+				width = 1
+			}
+		}
+	}
+	return width
+}
+
+// displayWidth returns the number of terminal columns s occupies; style
+// escape sequences take none
+func displayWidth(s string) int {
+	width := 0
+	for _, g := range graphemes(stripStyles(s)) {
+		width += graphemeWidth(g)
+	}
+	return width
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDisplayWidth(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"Rep: 3/15", 9},
+		{"终端健身房", 10},
+		{"收紧臀部！", 10},
+		{"🧘", 2},
+		{"🏋️", 2},          // text-default symbol with VS16
+		{"⏸️", 2},          // VS16 widens a narrow symbol
+		{"☺︎", 1},          // VS15 keeps text presentation
+		{"😮‍💨", 2},         // zero-width-joiner sequence
+		{"👍🏽", 2},          // skin tone modifier
+		{"🇨🇳", 2},          // flag
+		{"é", 1},           // combining acute accent
+		{"╭─────╮ ↖", 9},   // box drawing and arrows are narrow
+		{"🏋️ 终端健身房 🧘", 16}, // mixed
+		{"💡 Tips:", 8},
+	}
+
+	for _, tt := range tests {
+		if got := displayWidth(tt.text); got != tt.want {
+			t.Errorf("displayWidth(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestLayoutCenter(t *testing.T) {
+	layout := Layout{Width: 40, Height: 24}
+	for _, text := range []string{"abcd", "终端健身房", "🏋️ GYM 🧘"} {
+		line := layout.center(text)
+		left := len(line) - len(strings.TrimLeft(line, " "))
+		if want := (40 - displayWidth(text)) / 2; left != want {
+			t.Errorf("center(%q) indented by %d, want %d", text, left, want)
+		}
+	}
+}
+
+// TestLocalesCentered renders every instruction of every exercise in both
+// shipped locales and checks that the instruction line sits in the middle
+// of the terminal by display width, not by byte length.
+func TestLocalesCentered(t *testing.T) {
+	const width = 80
+
+	for _, lang := range []string{"en", "zh"} {
+		t.Run(lang, func(t *testing.T) {
+			localizer, err := NewLocalizer(lang)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, exercise := range []Exercise{NewButtockExercise(localizer), NewMeditationExercise(localizer)} {
+				gym := NewTerminalGym(localizer)
+				gym.setOutput(&bytes.Buffer{})
+				gym.layout = Layout{Width: width, Height: 50}
+				gym.currentExercise = exercise
+				gym.useExercise(1, 0)
+				gym.startStep(0)
+
+				// Walk through the phases, checking each new instruction
+				seen := map[string]bool{}
+				for i := 0; i < 30*fps && len(seen) < 4; i++ {
+					gym.workout.Update(physicsStep)
+					instruction := exercise.GetInstructions()
+					if seen[instruction] {
+						continue
+					}
+					seen[instruction] = true
+					assertCentered(t, gym.renderFrame().Lines(), instruction, width)
+				}
+
+				for _, key := range []string{"title", "subtitle", "watch_follow"} {
+					assertCentered(t, gym.renderFrame().Lines(), localizer.T(key), width)
+				}
+			}
+		})
+	}
+}
+
+// assertCentered finds text in lines and checks that the columns left and
+// right of it differ by at most one
+func assertCentered(t *testing.T, lines []string, text string, width int) {
+	t.Helper()
+
+	for _, line := range lines {
+		i := strings.Index(line, text)
+		if i < 0 {
+			continue
+		}
+		left := displayWidth(line[:i])
+		right := width - left - displayWidth(text)
+		if d := left - right; d < -1 || d > 1 {
+			t.Errorf("%q is off center: %d columns left, %d right", text, left, right)
+		}
+		return
+	}
+	t.Errorf("%q not found in frame", text)
+}